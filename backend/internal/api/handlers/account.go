@@ -3,23 +3,23 @@ package handlers
 import (
 	"net/http"
 	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
 	"wishlist-go/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-var accountService = service.NewAccountService()
-
 func DeleteAccount(c *gin.Context) {
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusOK, gin.H{})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
 	userID := auth.(*middleware.TelegramAuthData).User.ID
 
+	accountService := service.NewAccountService().WithContext(c.Request.Context())
 	if err := accountService.Delete(userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
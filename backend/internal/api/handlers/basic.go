@@ -13,3 +13,12 @@ func HealthCheck(c *gin.Context) {
 func OptionsHandler(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
+
+// NoRoute и NoMethod отдают неизвестные адреса в общем формате ошибок, а не текстом gin
+func NoRoute(c *gin.Context) {
+	abortWithError(c, errRouteNotFound)
+}
+
+func NoMethod(c *gin.Context) {
+	abortWithError(c, errMethodNotAllowed)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// calendarURL строит ссылку на ленту от server.public_url: заголовкам Host и X-Forwarded-* доверять нельзя,
+// иначе подделанный запрос получит ссылку с секретом на чужой адрес
+func calendarURL(token uuid.UUID) string {
+	return fmt.Sprintf("%s/calendar/%s.ics", strings.TrimSuffix(config.Config.Server.PublicURL, "/"), token)
+}
+
+func GetCalendarLink(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	calendarService := service.NewCalendarService().WithContext(c.Request.Context())
+	token, err := calendarService.Token(auth.(*middleware.TelegramAuthData).User.ID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": calendarURL(token)})
+}
+
+func RotateCalendarLink(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	calendarService := service.NewCalendarService().WithContext(c.Request.Context())
+	token, err := calendarService.RotateToken(auth.(*middleware.TelegramAuthData).User.ID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": calendarURL(token)})
+}
+
+// CalendarFeed отдает iCal-ленту без авторизации: доступ дает секрет в ссылке
+func CalendarFeed(c *gin.Context) {
+	token, err := uuid.Parse(strings.TrimSuffix(c.Param("token"), ".ics"))
+	if err != nil {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+
+	calendarService := service.NewCalendarService().WithContext(c.Request.Context())
+	feed, err := calendarService.Feed(token)
+	if errors.Is(err, service.ErrCalendarNotFound) {
+		c.String(http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "error building calendar")
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(feed))
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func PledgeContribution(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, role, ok := listAccess(c, userID, "")
+	if !ok {
+		return
+	}
+	if !isGuestView(role) {
+		abortWithError(c, errEditorCantContribute)
+		return
+	}
+	wishID, err := strconv.ParseInt(c.Param("wishId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidWishItemID)
+		return
+	}
+
+	type req struct {
+		Amount   float64 `json:"amount" binding:"required"`
+		Currency string  `json:"currency"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	contributionService := service.NewContributionService().WithContext(c.Request.Context())
+	contribution, err := contributionService.Pledge(wishlist.ShareCode, wishID, userID, r.Amount, r.Currency)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contribution": contribution})
+}
+
+func WithdrawContribution(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, "")
+	if !ok {
+		return
+	}
+	wishID, err := strconv.ParseInt(c.Param("wishId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidWishItemID)
+		return
+	}
+
+	contributionService := service.NewContributionService().WithContext(c.Request.Context())
+	err = contributionService.Withdraw(wishlist.ShareCode, wishID, userID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "contribution withdrawn"})
+}
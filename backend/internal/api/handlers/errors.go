@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ошибки разбора запроса; доменные ошибки сервисов (в том числе "не найдено")
+// передаются как есть, статус по ним выбирает middleware.Errors
+var (
+	errInvalidLimit       = apperr.New(apperr.KindValidation, "invalid_limit", "invalid limit")
+	errInvalidWishItemID  = apperr.New(apperr.KindValidation, "invalid_wish_item_id", "invalid wish item id")
+	errInvalidAccountID   = apperr.New(apperr.KindValidation, "invalid_account_id", "invalid account id")
+	errInvalidInviteCode  = apperr.New(apperr.KindValidation, "invalid_invite_code", "invalid invite code")
+	errInvalidShareLinkID = apperr.New(apperr.KindValidation, "invalid_share_link_id", "invalid share link id")
+	errInvalidTelegramID  = apperr.New(apperr.KindValidation, "invalid_telegram_id", "invalid telegram id")
+	errInvalidUserID      = apperr.New(apperr.KindValidation, "invalid_user_id", "invalid user id")
+	errInvalidTagID       = apperr.New(apperr.KindValidation, "invalid_tag_id", "invalid tag id")
+
+	errRouteNotFound    = apperr.New(apperr.KindNotFound, "route_not_found", "route not found")
+	errMethodNotAllowed = apperr.New(apperr.KindMethodNotAllowed, "method_not_allowed", "method not allowed")
+
+	// редакторы и владельцы не видят резервов, участвовать в подарке им незачем
+	errEditorCantReserve    = apperr.New(apperr.KindForbidden, "editor_cannot_reserve", "list editors can't reserve wishes")
+	errEditorCantContribute = apperr.New(apperr.KindForbidden, "editor_cannot_contribute", "list editors can't contribute to wishes")
+)
+
+// abortWithError прерывает обработку; ответ с ошибкой сформирует middleware.Errors
+func abortWithError(c *gin.Context, err error) {
+	middleware.AbortWithError(c, err)
+}
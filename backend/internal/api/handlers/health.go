@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/db"
+	"wishlist-go/internal/version"
+	"wishlist-go/internal/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+const readinessTimeout = 2 * time.Second
+
+var (
+	errWorkerNotStarted = errors.New("worker has not completed a run yet")
+	errWorkerStalled    = errors.New("worker heartbeat is stale")
+)
+
+// воркер считается зависшим, если пропустил несколько проходов подряд
+const missedHeartbeats = 3
+
+type healthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Detail any    `json:"detail,omitempty"`
+}
+
+// Liveness - процесс жив и обрабатывает запросы; зависимости не проверяются
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "version": version.Get()})
+}
+
+// Readiness - сервис готов принимать трафик: база отвечает, миграции применены, воркер работает
+func Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	checks := map[string]healthCheck{}
+	ready := true
+	report := func(name string, detail any, err error) {
+		if err != nil {
+			ready = false
+			checks[name] = healthCheck{Status: "fail", Error: err.Error(), Detail: detail}
+			return
+		}
+		checks[name] = healthCheck{Status: "ok", Detail: detail}
+	}
+
+	report("database", nil, db.Ping(ctx))
+	if migrations, err := db.Migrations(ctx); err != nil {
+		report("migrations", nil, err)
+	} else {
+		report("migrations", migrations, nil)
+	}
+	workerState, err := workerDetail()
+	report("worker", workerState, err)
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "fail", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks, "version": version.Get()})
+}
+
+func workerDetail() (gin.H, error) {
+	last := worker.Heartbeat()
+	if last.IsZero() {
+		return nil, errWorkerNotStarted
+	}
+	age := time.Since(last)
+	detail := gin.H{"last_heartbeat": last.UTC().Format(time.RFC3339), "age_seconds": int64(age.Seconds())}
+	if age > missedHeartbeats*config.Config.Worker.Interval {
+		return detail, errWorkerStalled
+	}
+	return detail, nil
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// listAccess находит список по listId (ShareCode для участников или ссылка для гостей)
+// и проверяет роль пользователя в нем.
+// required == "" пропускает и посторонних (гостей по ссылке), их роль будет пустой.
+// При отказе ответ уже записан в контекст.
+func listAccess(c *gin.Context, userID int64, required string) (*models.WishList, string, bool) {
+	wishlist, role, err := service.NewShareTokenService().WithContext(c.Request.Context()).Resolve(c.Param("listId"), userID)
+	if err != nil {
+		abortWithError(c, err)
+		return nil, "", false
+	}
+
+	err = service.CheckListPolicy(c.Request.Context(), wishlist, role, userID, c.GetHeader(listGrantHeader))
+	if err != nil {
+		abortWithError(c, err)
+		return nil, "", false
+	}
+	if required != "" && !service.RoleAtLeast(role, required) {
+		abortWithError(c, service.ErrForbidden)
+		return nil, role, false
+	}
+	return wishlist, role, true
+}
+
+// hideShareCode убирает из ответа гостю постоянный идентификатор списка,
+// оставляя ссылку, по которой он пришел. Вызывать непосредственно перед ответом.
+func hideShareCode(c *gin.Context, wishlist *models.WishList, wishItems []models.WishItem) {
+	if wishlist != nil {
+		wishlist.ShareCode = uuid.Nil
+		wishlist.ShareToken = c.Param("listId")
+	}
+	for i := range wishItems {
+		wishItems[i].WishListCode = uuid.Nil
+	}
+}
+
+// inviteLink - ссылка на мини-приложение, которая передаст код приглашения в start_param
+func inviteLink(code uuid.UUID) string {
+	if config.Config.Telegram.WebAppURL == "" {
+		return ""
+	}
+	return config.Config.Telegram.WebAppURL + "?startapp=invite_" + code.String()
+}
+
+func GetMembers(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleViewer)
+	if !ok {
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	members, err := membershipService.Members(wishlist)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func UpdateMember(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+	accountID, err := strconv.ParseInt(c.Param("accountId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidAccountID)
+		return
+	}
+
+	type req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	err = membershipService.UpdateRole(wishlist, accountID, r.Role)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "member updated"})
+}
+
+func RemoveMember(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	accountID, err := strconv.ParseInt(c.Param("accountId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidAccountID)
+		return
+	}
+
+	// выйти из списка может любой участник, исключать других - только владелец
+	required := models.RoleOwner
+	if accountID == userID {
+		required = models.RoleViewer
+	}
+	wishlist, _, ok := listAccess(c, userID, required)
+	if !ok {
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	err = membershipService.RemoveMember(wishlist, accountID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+func GetInvites(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	invites, err := membershipService.Invites(wishlist)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+func CreateInvite(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	type req struct {
+		Role           string `json:"role" binding:"required"`
+		ExpiresInHours int    `json:"expires_in_hours" binding:"min=0"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	invite, err := membershipService.CreateInvite(wishlist, userID, r.Role, time.Duration(r.ExpiresInHours)*time.Hour)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invite": invite, "link": inviteLink(invite.Code)})
+}
+
+func RevokeInvite(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+	code, err := uuid.Parse(c.Param("code"))
+	if err != nil {
+		abortWithError(c, errInvalidInviteCode)
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	err = membershipService.RevokeInvite(wishlist, code)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "invite revoked"})
+}
+
+func AcceptInvite(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	code, err := uuid.Parse(c.Param("code"))
+	if err != nil {
+		abortWithError(c, errInvalidInviteCode)
+		return
+	}
+
+	membershipService := service.NewMembershipService().WithContext(c.Request.Context())
+	wishlist, role, err := membershipService.AcceptInvite(code, auth.(*middleware.TelegramAuthData).User.ID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"wishlist": wishlist, "role": role})
+}
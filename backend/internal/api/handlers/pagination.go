@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageRequest читает limit, cursor и sort из query; limit больше service.MaxPageSize урезается.
+// При ошибке ответ уже записан в контекст.
+func pageRequest(c *gin.Context) (service.PageRequest, bool) {
+	page := service.PageRequest{Cursor: c.Query("cursor"), Sort: c.Query("sort")}
+	if limit := c.Query("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 {
+			abortWithError(c, errInvalidLimit)
+			return page, false
+		}
+		page.Limit = limitInt
+	}
+	return page, true
+}
+
+// wishItemFilter читает условия отбора желаний из query: q, status (через запятую),
+// price_min, price_max, currency, priority, tags (через запятую). При ошибке ответ уже записан в контекст.
+func wishItemFilter(c *gin.Context) (service.WishItemFilter, bool) {
+	var q struct {
+		Query    string   `form:"q" json:"q" binding:"max=200"`
+		Statuses []string `form:"status" json:"status" collection_format:"csv" binding:"dive,oneof=pending reserved funded purchased"`
+		PriceMin *float64 `form:"price_min" json:"price_min" binding:"omitempty,min=0"`
+		PriceMax *float64 `form:"price_max" json:"price_max" binding:"omitempty,min=0"`
+		Currency string   `form:"currency" json:"currency" binding:"omitempty,currency"`
+		Priority *int     `form:"priority" json:"priority" binding:"omitempty,min=0,max=10"`
+		TagIDs   []int64  `form:"tags" json:"tags" collection_format:"csv" binding:"max=20"`
+	}
+	if !bindQuery(c, &q) {
+		return service.WishItemFilter{}, false
+	}
+	return service.WishItemFilter{
+		Query:    strings.TrimSpace(q.Query),
+		Statuses: q.Statuses,
+		PriceMin: q.PriceMin,
+		PriceMax: q.PriceMax,
+		Currency: q.Currency,
+		Priority: q.Priority,
+		TagIDs:   q.TagIDs,
+	}, true
+}
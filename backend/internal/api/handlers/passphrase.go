@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listGrantHeader - заголовок, в котором гость передает доступ, полученный после ввода кодовой фразы
+const listGrantHeader = "X-List-Grant"
+
+func UnlockWishlist(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+
+	// listAccess не подходит: он сам требует доступ, который здесь только выдается
+	wishlist, role, err := service.NewShareTokenService().WithContext(c.Request.Context()).Resolve(c.Param("listId"), userID)
+	if err == nil {
+		err = service.CheckListPolicy(c.Request.Context(), wishlist, role, userID, "")
+	}
+	if err != nil && !errors.Is(err, service.ErrPassphraseRequired) {
+		abortWithError(c, err)
+		return
+	}
+
+	type req struct {
+		Passphrase string `json:"passphrase" binding:"required"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	passphraseService := service.NewPassphraseService().WithContext(c.Request.Context())
+	grant, expiresAt, retryAfter, err := passphraseService.Unlock(wishlist, userID, r.Passphrase)
+	switch {
+	case errors.Is(err, service.ErrTooManyAttempts):
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		abortWithError(c, err)
+		return
+	case err != nil:
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"grant": grant, "expires_at": expiresAt.Unix()})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func GetAllowedUsers(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	policyService := service.NewPolicyService().WithContext(c.Request.Context())
+	users, err := policyService.AllowedUsers(wishlist)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"allowed_users": users})
+}
+
+func AllowUser(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	type req struct {
+		TelegramID int64 `json:"telegram_id" binding:"required"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	policyService := service.NewPolicyService().WithContext(c.Request.Context())
+	user, err := policyService.AllowUser(wishlist, r.TelegramID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"allowed_user": user})
+}
+
+func DisallowUser(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+	telegramID, err := strconv.ParseInt(c.Param("telegramId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidTelegramID)
+		return
+	}
+
+	policyService := service.NewPolicyService().WithContext(c.Request.Context())
+	err = policyService.DisallowUser(wishlist, telegramID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user disallowed"})
+}
+
+// GetPublicWishlists - публичный профиль: списки пользователя с видимостью public
+func GetPublicWishlists(c *gin.Context) {
+	if _, exist := c.Get("telegram_auth"); !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	ownerID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidUserID)
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	wishlistService := service.NewWishlistService().WithContext(c.Request.Context())
+	result, err := wishlistService.GetPublicByOwner(ownerID, page)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	wishlists := result.Items
+	service.NewOccasionService().WithContext(c.Request.Context()).FillDaysLeft(wishlists)
+
+	// в профиле вместо постоянного ShareCode выдаем отдельную отзываемую ссылку
+	tokens, err := service.NewShareTokenService().WithContext(c.Request.Context()).PublicTokens(wishlists)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	for i := range wishlists {
+		wishlists[i].ShareCode = uuid.Nil
+		wishlists[i].ShareToken = tokens[wishlists[i].ID]
+	}
+	c.JSON(http.StatusOK, gin.H{"wishlists": wishlists, "next_cursor": result.NextCursor, "total": result.Total})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ReserveWishItem(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, role, ok := listAccess(c, userID, "")
+	if !ok {
+		return
+	}
+	if !isGuestView(role) {
+		abortWithError(c, errEditorCantReserve)
+		return
+	}
+	wishID, err := strconv.ParseInt(c.Param("wishId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidWishItemID)
+		return
+	}
+
+	type req struct {
+		Quantity int `json:"quantity"`
+	}
+	var r req
+	// тело необязательно: по умолчанию резервируется одна единица
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &r) {
+			return
+		}
+	}
+	if r.Quantity == 0 {
+		r.Quantity = 1
+	}
+
+	reservationService := service.NewReservationService().WithContext(c.Request.Context())
+	reservation, err := reservationService.Reserve(wishlist.ShareCode, wishID, userID, r.Quantity)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
+
+func CancelReservation(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, "")
+	if !ok {
+		return
+	}
+	wishID, err := strconv.ParseInt(c.Param("wishId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidWishItemID)
+		return
+	}
+
+	reservationService := service.NewReservationService().WithContext(c.Request.Context())
+	err = reservationService.Cancel(wishlist.ShareCode, wishID, userID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "reservation cancelled"})
+}
+
+// RenewReservation продлевает свой резерв на срок из настроек списка
+func RenewReservation(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, "")
+	if !ok {
+		return
+	}
+	wishID, err := strconv.ParseInt(c.Param("wishId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidWishItemID)
+		return
+	}
+
+	reservationService := service.NewReservationService().WithContext(c.Request.Context())
+	reservation, err := reservationService.Renew(wishlist.ShareCode, wishID, userID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetShareTokens(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	shareTokenService := service.NewShareTokenService().WithContext(c.Request.Context())
+	tokens, err := shareTokenService.GetAll(wishlist)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"share_tokens": tokens})
+}
+
+func CreateShareToken(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, models.RoleOwner)
+	if !ok {
+		return
+	}
+
+	type req struct {
+		Label          string `json:"label"`
+		ExpiresInHours int    `json:"expires_in_hours" binding:"min=0"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	shareTokenService := service.NewShareTokenService().WithContext(c.Request.Context())
+	token, err := shareTokenService.Create(wishlist, userID, r.Label, time.Duration(r.ExpiresInHours)*time.Hour)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"share_token": token})
+}
+
+func RotateShareToken(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, models.RoleOwner)
+	if !ok {
+		return
+	}
+	tokenID, err := strconv.ParseInt(c.Param("tokenId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidShareLinkID)
+		return
+	}
+
+	shareTokenService := service.NewShareTokenService().WithContext(c.Request.Context())
+	token, err := shareTokenService.Rotate(wishlist, tokenID, userID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"share_token": token})
+}
+
+func RevokeShareToken(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
+		return
+	}
+	tokenID, err := strconv.ParseInt(c.Param("tokenId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidShareLinkID)
+		return
+	}
+
+	shareTokenService := service.NewShareTokenService().WithContext(c.Request.Context())
+	err = shareTokenService.Revoke(wishlist, tokenID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "share link revoked"})
+}
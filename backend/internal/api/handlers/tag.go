@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetTags(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	tagService := service.NewTagService().WithContext(c.Request.Context())
+	tags, err := tagService.GetAll(auth.(*middleware.TelegramAuthData).User.ID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// GetListTags отдает метки владельца списка: редактор ставит желаниям чужого списка именно их
+func GetListTags(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	tagService := service.NewTagService().WithContext(c.Request.Context())
+	tags, err := tagService.GetAll(wishlist.OwnerID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func CreateTag(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	type req struct {
+		Name string `json:"name" binding:"required,max=50"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	tagService := service.NewTagService().WithContext(c.Request.Context())
+	tag, err := tagService.Create(auth.(*middleware.TelegramAuthData).User.ID, r.Name)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+func RenameTag(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	tagID, err := strconv.ParseInt(c.Param("tagId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidTagID)
+		return
+	}
+
+	type req struct {
+		Name string `json:"name" binding:"required,max=50"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	tagService := service.NewTagService().WithContext(c.Request.Context())
+	tag, err := tagService.Rename(auth.(*middleware.TelegramAuthData).User.ID, tagID, r.Name)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+func DeleteTag(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	tagID, err := strconv.ParseInt(c.Param("tagId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidTagID)
+		return
+	}
+
+	tagService := service.NewTagService().WithContext(c.Request.Context())
+	if err := tagService.Delete(auth.(*middleware.TelegramAuthData).User.ID, tagID); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"wishlist-go/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var errValidationFailed = apperr.New(apperr.KindValidation, "validation_failed", "request validation failed")
+
+// FieldError - ошибка одного поля тела запроса: имя поля из JSON, нарушенное правило и его параметр
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// в ошибках по полям клиент видит имена из JSON, а не из Go-структур
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// weblink - пустая строка (ссылку убрали) или адрес http(s)
+	v.RegisterAlias("weblink", "eq=|http_url")
+	// currency - код валюты ISO 4217 заглавными буквами, например RUB
+	v.RegisterAlias("currency", "iso4217")
+}
+
+// bindJSON разбирает тело запроса и проверяет его по тегам binding.
+// При ошибке ответ уже записан в контекст: нарушенные правила перечисляются по полям в details.
+func bindJSON(c *gin.Context, obj any) bool {
+	return checkBinding(c, c.ShouldBindJSON(obj))
+}
+
+// bindQuery - то же для параметров строки запроса; имена полей в ошибках берутся из тега json,
+// поэтому он должен совпадать с тегом form
+func bindQuery(c *gin.Context, obj any) bool {
+	return checkBinding(c, c.ShouldBindQuery(obj))
+}
+
+func checkBinding(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		abortWithError(c, apperr.ErrInvalidRequest)
+		return false
+	}
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, FieldError{Field: fieldErr.Field(), Rule: fieldErr.Tag(), Param: fieldErr.Param()})
+	}
+	abortWithError(c, errValidationFailed.WithDetails(gin.H{"fields": fields}))
+	return false
+}
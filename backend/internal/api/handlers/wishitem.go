@@ -4,98 +4,112 @@ import (
 	"net/http"
 	"strconv"
 	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
 	"wishlist-go/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-var wishItemService = service.NewWishItemService()
+// isGuestView - гости и зрители видят резервы и сборы, редакторы и владельцы нет, чтобы не портить сюрприз
+func isGuestView(role string) bool {
+	return !service.RoleAtLeast(role, models.RoleEditor)
+}
+
+// fillGuestView дополняет желания сведениями, которые видит только гость списка
+func fillGuestView(c *gin.Context, wishItems []models.WishItem) error {
+	if err := service.NewReservationService().WithContext(c.Request.Context()).FillReserved(wishItems); err != nil {
+		return err
+	}
+	return service.NewContributionService().WithContext(c.Request.Context()).FillFunded(wishItems)
+}
 
 func GetWishItems(c *gin.Context) {
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusOK, gin.H{})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
 
-	listId := c.Param("listId")
-	shareCode, err := uuid.Parse(listId)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"msg": "invalid list id"})
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, role, ok := listAccess(c, userID, "")
+	if !ok {
 		return
 	}
 
-	limit := c.Query("limit")
-	if limit == "" {
-		limit = "10"
-	}
-	offset := c.Query("offset")
-	if offset == "" {
-		offset = "0"
-	}
-
-	limitInt, err := strconv.Atoi(limit)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"msg": "invalid limit"})
+	page, ok := pageRequest(c)
+	if !ok {
 		return
 	}
-	offsetInt, err := strconv.Atoi(offset)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"msg": "invalid offset"})
+	filter, ok := wishItemFilter(c)
+	if !ok {
 		return
 	}
 
-	wishItemService.Owner = &auth.(*middleware.TelegramAuthData).User.ID
-	wishItemService.WishList = shareCode
-	wishItems, err := wishItemService.GetAll(limitInt, offsetInt)
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	wishItemService.Owner = &userID
+	wishItemService.WishList = wishlist.ShareCode
+	result, err := wishItemService.GetAll(filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"msg": "error fetching wish items"})
+		abortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"wish_items": wishItems})
+	wishItems := result.Items
+
+	if role == "" {
+		// просмотр чужого списка нужен для напоминаний о поводе
+		if err := service.NewOccasionService().WithContext(c.Request.Context()).RecordView(wishlist.ID, userID); err != nil {
+			abortWithError(c, err)
+			return
+		}
+	}
+	if isGuestView(role) {
+		if err := fillGuestView(c, wishItems); err != nil {
+			abortWithError(c, err)
+			return
+		}
+	}
+	if role == "" {
+		hideShareCode(c, nil, wishItems)
+	}
+	c.JSON(http.StatusOK, gin.H{"wish_items": wishItems, "next_cursor": result.NextCursor, "total": result.Total})
 
 }
 
 func CreateWishItem(c *gin.Context) {
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-
-	listId := c.Param("listId")
-	wishListCode, err := uuid.Parse(listId)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
 
-	wlService := service.NewWishlistService()
-	hasAccess := wlService.CheckAccess(auth.(*middleware.TelegramAuthData).User.ID, wishListCode)
-	if !hasAccess {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
 		return
 	}
 
 	type req struct {
-		Name           string  `json:"name" binding:"required"`
-		Priority       int     `json:"priority"`
-		MarketLink     string  `json:"market_link"`
-		MarketPicture  string  `json:"market_picture"`
-		MarketPrice    float64 `json:"market_price"`
-		MarketCurrency string  `json:"market_currency"`
-		MarketQuantity int     `json:"market_quantity"`
+		Name           string  `json:"name" binding:"required,max=200"`
+		Priority       int     `json:"priority" binding:"min=0,max=10"`
+		MarketLink     string  `json:"market_link" binding:"max=2048,weblink"`
+		MarketPicture  string  `json:"market_picture" binding:"max=2048,weblink"`
+		MarketPrice    float64 `json:"market_price" binding:"min=0"`
+		MarketCurrency string  `json:"market_currency" binding:"omitempty,currency"`
+		MarketQuantity int     `json:"market_quantity" binding:"min=0,max=1000"`
+		Notes          string  `json:"notes" binding:"max=2000"`
+		TagIDs         []int64 `json:"tag_ids" binding:"max=20"`
 	}
 	var r req
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	if !bindJSON(c, &r) {
 		return
 	}
 	defaultStatus := "pending"
 
-	wishItemService.WishList = wishListCode
-	wishItemService.Owner = &auth.(*middleware.TelegramAuthData).User.ID
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	// желание принадлежит владельцу списка, даже если его добавил редактор
+	wishItemService.WishList = wishlist.ShareCode
+	wishItemService.Owner = &wishlist.OwnerID
 	wishItemService.Name = &r.Name
 	wishItemService.Priority = &r.Priority
 	wishItemService.Status = &defaultStatus // pending by default
@@ -104,9 +118,11 @@ func CreateWishItem(c *gin.Context) {
 	wishItemService.MarketPrice = &r.MarketPrice
 	wishItemService.MarketCurrency = &r.MarketCurrency
 	wishItemService.MarketQuantity = &r.MarketQuantity
+	wishItemService.Notes = &r.Notes
+	wishItemService.TagIDs = &r.TagIDs
 	wishItem, err := wishItemService.Create()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating wish item"})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"wish_item": wishItem})
@@ -115,56 +131,83 @@ func CreateWishItem(c *gin.Context) {
 func GetWishItem(c *gin.Context) {
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	wishlist, role, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, "")
+	if !ok {
 		return
 	}
 
 	wishItemID := c.Param("wishId")
 	id, err := strconv.ParseInt(wishItemID, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish item id"})
+		abortWithError(c, errInvalidWishItemID)
 		return
 	}
 
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
 	wishItemService.Owner = &auth.(*middleware.TelegramAuthData).User.ID
+	wishItemService.WishList = wishlist.ShareCode
 	wishItem, err := wishItemService.Get(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching wish item"})
+		abortWithError(c, err)
 		return
 	}
+
+	if isGuestView(role) {
+		wishItems := []models.WishItem{*wishItem}
+		if err := fillGuestView(c, wishItems); err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if role == "" {
+			hideShareCode(c, nil, wishItems)
+		}
+		wishItem = &wishItems[0]
+	}
 	c.JSON(http.StatusOK, gin.H{"wish_item": wishItem})
 }
 
 func UpdateWishItem(c *gin.Context) {
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
 		return
 	}
 
 	wishItemID := c.Param("wishId")
 	id, err := strconv.ParseInt(wishItemID, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish item id"})
+		abortWithError(c, errInvalidWishItemID)
 		return
 	}
 
+	// те же правила, что при создании; отсутствующие поля не проверяются и не меняются
 	type req struct {
-		Name           *string  `json:"name"`
-		Priority       *int     `json:"priority"`
-		Status         *string  `json:"status"`
-		MarketLink     *string  `json:"market_link"`
-		MarketPicture  *string  `json:"market_picture"`
-		MarketPrice    *float64 `json:"market_price"`
-		MarketCurrency *string  `json:"market_currency"`
-		MarketQuantity *int     `json:"market_quantity"`
+		Name           *string  `json:"name" binding:"omitempty,min=1,max=200"`
+		Priority       *int     `json:"priority" binding:"omitempty,min=0,max=10"`
+		Status         *string  `json:"status" binding:"omitempty,oneof=pending reserved funded purchased"`
+		MarketLink     *string  `json:"market_link" binding:"omitempty,max=2048,weblink"`
+		MarketPicture  *string  `json:"market_picture" binding:"omitempty,max=2048,weblink"`
+		MarketPrice    *float64 `json:"market_price" binding:"omitempty,min=0"`
+		MarketCurrency *string  `json:"market_currency" binding:"omitempty,currency"`
+		MarketQuantity *int     `json:"market_quantity" binding:"omitempty,min=0,max=1000"`
+		Notes          *string  `json:"notes" binding:"omitempty,max=2000"`
+		TagIDs         *[]int64 `json:"tag_ids" binding:"omitempty,max=20"`
 	}
 	var r req
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	if !bindJSON(c, &r) {
 		return
 	}
-	wishItemService.Owner = &auth.(*middleware.TelegramAuthData).User.ID
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	wishItemService.WishList = wishlist.ShareCode
 	wishItemService.Name = r.Name
 	wishItemService.Priority = r.Priority
 	wishItemService.Status = r.Status
@@ -173,10 +216,12 @@ func UpdateWishItem(c *gin.Context) {
 	wishItemService.MarketPrice = r.MarketPrice
 	wishItemService.MarketCurrency = r.MarketCurrency
 	wishItemService.MarketQuantity = r.MarketQuantity
+	wishItemService.Notes = r.Notes
+	wishItemService.TagIDs = r.TagIDs
 
 	wishItem, err := wishItemService.Update(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating wish item"})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"wish_item": wishItem})
@@ -185,22 +230,153 @@ func UpdateWishItem(c *gin.Context) {
 func DeleteWishItem(c *gin.Context) {
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
 		return
 	}
 
 	wishItemID := c.Param("wishId")
 	id, err := strconv.ParseInt(wishItemID, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wish item id"})
+		abortWithError(c, errInvalidWishItemID)
 		return
 	}
 
-	wishItemService.Owner = &auth.(*middleware.TelegramAuthData).User.ID
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	wishItemService.WishList = wishlist.ShareCode
 	err = wishItemService.Delete(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting wish item"})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "wish item deleted"})
 }
+
+// MoveWishItem ставит желание перед или после другого желания того же списка
+func MoveWishItem(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("wishId"), 10, 64)
+	if err != nil {
+		abortWithError(c, errInvalidWishItemID)
+		return
+	}
+
+	// указывается ровно один сосед
+	type req struct {
+		Before *int64 `json:"before" binding:"required_without=After,excluded_with=After"`
+		After  *int64 `json:"after" binding:"required_without=Before"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	wishItemService.WishList = wishlist.ShareCode
+	var wishItem *models.WishItem
+	if r.Before != nil {
+		wishItem, err = wishItemService.Move(id, *r.Before, true)
+	} else {
+		wishItem, err = wishItemService.Move(id, *r.After, false)
+	}
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"wish_item": wishItem})
+}
+
+// TransferWishItems переносит желания в другой список, который пользователь может редактировать
+func TransferWishItems(c *gin.Context) {
+	transferWishItems(c, false)
+}
+
+// CopyWishItems копирует желания в другой список, который пользователь может редактировать
+func CopyWishItems(c *gin.Context) {
+	transferWishItems(c, true)
+}
+
+func transferWishItems(c *gin.Context, copyItems bool) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, _, ok := listAccess(c, userID, models.RoleEditor)
+	if !ok {
+		return
+	}
+
+	type req struct {
+		WishIDs    []int64 `json:"wish_ids" binding:"required,min=1,max=100"`
+		TargetList string  `json:"target_list" binding:"required,uuid"`
+	}
+	var r req
+	if !bindJSON(c, &r) {
+		return
+	}
+
+	target, _, err := service.NewMembershipService().WithContext(c.Request.Context()).
+		Authorize(userID, uuid.MustParse(r.TargetList), models.RoleEditor)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	wishItemService.WishList = wishlist.ShareCode
+	var wishItems []models.WishItem
+	if copyItems {
+		wishItems, err = wishItemService.CopyTo(target, r.WishIDs)
+	} else {
+		wishItems, err = wishItemService.MoveTo(target, r.WishIDs)
+	}
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"wish_items": wishItems})
+}
+
+// SearchWishItems ищет желания во всех списках пользователя, своих и тех, где он участник.
+// Резервы и сборы не подставляются: в своих списках их не показывают владельцу и редакторам.
+func SearchWishItems(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	filter, ok := wishItemFilter(c)
+	if !ok {
+		return
+	}
+
+	wishItemService := service.NewWishItemService().WithContext(c.Request.Context())
+	result, err := wishItemService.Search(auth.(*middleware.TelegramAuthData).User.ID, filter, page)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"wish_items": result.Items, "next_cursor": result.NextCursor, "total": result.Total})
+}
@@ -2,149 +2,208 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
+	"time"
 	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
 	"wishlist-go/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 func GetWishlists(c *gin.Context) {
-	var wishlistService = service.NewWishlistService()
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusOK, gin.H{})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
 
-	offset := c.Query("offset")
-	if offset == "" {
-		offset = "0"
-	}
-	limit := c.Query("limit")
-	if limit == "" {
-		limit = "10"
+	page, ok := pageRequest(c)
+	if !ok {
+		return
 	}
-	offsetInt, err := strconv.Atoi(offset)
+
+	owner := &auth.(*middleware.TelegramAuthData).User.ID
+
+	wishlistService := service.NewWishlistService().WithContext(c.Request.Context())
+	wishlists, err := wishlistService.GetAllForAccount(*owner, page)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"msg": "invalid offset"})
+		abortWithError(c, err)
 		return
 	}
-	limitInt, err := strconv.Atoi(limit)
+	service.NewOccasionService().WithContext(c.Request.Context()).FillDaysLeft(wishlists.Items)
+	c.JSON(http.StatusOK, gin.H{"wishlists": wishlists.Items, "next_cursor": wishlists.NextCursor, "total": wishlists.Total})
+}
+
+// parseOccasionDate разбирает дату повода; пустая строка превращается в нулевое время
+func parseOccasionDate(value *string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if *value == "" {
+		return &time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, *value)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"msg": "invalid limit"})
-		return
+		return nil, err
 	}
+	return &date, nil
+}
 
-	owner := &auth.(*middleware.TelegramAuthData).User.ID
+func GetWishlist(c *gin.Context) {
+	auth, exist := c.Get("telegram_auth")
+	if !exist {
+		abortWithError(c, apperr.ErrUnauthorized)
+		return
+	}
 
-	wishlists, err := wishlistService.GetAllByOwner(*owner, limitInt, offsetInt)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"msg": "error fetching wishlists"})
+	userID := auth.(*middleware.TelegramAuthData).User.ID
+	wishlist, role, ok := listAccess(c, userID, "")
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"wishlists": wishlists})
+
+	if role == "" {
+		if err := service.NewOccasionService().WithContext(c.Request.Context()).RecordView(wishlist.ID, userID); err != nil {
+			abortWithError(c, err)
+			return
+		}
+	}
+	wishlist.DaysLeft = service.DaysLeft(wishlist, time.Now())
+	if role == "" {
+		hideShareCode(c, wishlist, nil)
+	}
+	c.JSON(http.StatusOK, gin.H{"wishlist": wishlist, "role": role})
 }
 
 func CreateWishlist(c *gin.Context) {
-	var wishlistService = service.NewWishlistService()
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
 
 	type req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
+		Name               string  `json:"name" binding:"required,max=200"`
+		Description        string  `json:"description" binding:"max=2000"`
+		ReservationTTLDays *int    `json:"reservation_ttl_days" binding:"omitempty,min=0,max=365"`
+		OccasionType       *string `json:"occasion_type"`
+		OccasionDate       *string `json:"occasion_date"` // YYYY-MM-DD
+		OccasionRecurrence *string `json:"occasion_recurrence"`
+		Visibility         *string `json:"visibility"`
+		Passphrase         *string `json:"passphrase" binding:"omitempty,max=72"`
 	}
 	var r req
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	if !bindJSON(c, &r) {
 		return
 	}
-
-	wl := service.WishlistInsert{
-		Owner:       &auth.(*middleware.TelegramAuthData).User.ID,
-		Name:        &r.Name,
-		Description: &r.Description,
+	occasionDate, err := parseOccasionDate(r.OccasionDate)
+	if err != nil || service.ValidateOccasion(r.OccasionType, r.OccasionRecurrence) != nil {
+		abortWithError(c, service.ErrInvalidOccasion)
+		return
+	}
+	if r.Visibility != nil && !service.ValidVisibility(*r.Visibility) {
+		abortWithError(c, service.ErrInvalidVisibility)
+		return
 	}
 
+	wl := service.WishlistInsert{
+		Owner:              &auth.(*middleware.TelegramAuthData).User.ID,
+		Name:               &r.Name,
+		Description:        &r.Description,
+		ReservationTTLDays: r.ReservationTTLDays,
+		OccasionType:       r.OccasionType,
+		OccasionDate:       occasionDate,
+		OccasionRecurrence: r.OccasionRecurrence,
+		Visibility:         r.Visibility,
+		Passphrase:         r.Passphrase,
+	}
+
+	wishlistService := service.NewWishlistService().WithContext(c.Request.Context())
 	wishlist, err := wishlistService.Create(&wl)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating wishlist"})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"wishlist": wishlist})
 }
 
 func UpdateWishlist(c *gin.Context) {
-	var wishlistService = service.NewWishlistService()
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
-	listId := c.Param("listId")
-	shareCode, err := uuid.Parse(listId)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
-		return
-	}
-
-	// Проверяем, есть ли у пользователя доступ к этому списку желаний
-	hasAccess := wishlistService.CheckAccess(auth.(*middleware.TelegramAuthData).User.ID, shareCode)
-	if !hasAccess {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	// менять список могут редакторы и владельцы
+	current, role, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleEditor)
+	if !ok {
 		return
 	}
 
 	type req struct {
-		Name        *string `json:"name"`
-		Description *string `json:"description"`
+		Name               *string `json:"name" binding:"omitempty,min=1,max=200"`
+		Description        *string `json:"description" binding:"omitempty,max=2000"`
+		ReservationTTLDays *int    `json:"reservation_ttl_days" binding:"omitempty,min=0,max=365"`
+		OccasionType       *string `json:"occasion_type"`
+		OccasionDate       *string `json:"occasion_date"` // YYYY-MM-DD, пустая строка убирает дату
+		OccasionRecurrence *string `json:"occasion_recurrence"`
+		Visibility         *string `json:"visibility"`
+		Passphrase         *string `json:"passphrase" binding:"omitempty,max=72"` // пустая строка снимает защиту
 	}
 	var r req
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	if !bindJSON(c, &r) {
+		return
+	}
+	occasionDate, err := parseOccasionDate(r.OccasionDate)
+	if err != nil || service.ValidateOccasion(r.OccasionType, r.OccasionRecurrence) != nil {
+		abortWithError(c, service.ErrInvalidOccasion)
+		return
+	}
+	if r.Visibility != nil && !service.ValidVisibility(*r.Visibility) {
+		abortWithError(c, service.ErrInvalidVisibility)
+		return
+	}
+	// кому виден список и чем он защищен, решает только владелец, как и со ссылками и белым списком
+	if (r.Visibility != nil || r.Passphrase != nil) && !service.RoleAtLeast(role, models.RoleOwner) {
+		abortWithError(c, service.ErrForbidden)
 		return
 	}
 
-	wishlist, err := wishlistService.Update(shareCode, service.WishlistInsert{
-		Name:        r.Name,
-		Description: r.Description,
+	wishlistService := service.NewWishlistService().WithContext(c.Request.Context())
+	wishlist, err := wishlistService.Update(current.ShareCode, service.WishlistInsert{
+		Name:               r.Name,
+		Description:        r.Description,
+		ReservationTTLDays: r.ReservationTTLDays,
+		OccasionType:       r.OccasionType,
+		OccasionDate:       occasionDate,
+		OccasionRecurrence: r.OccasionRecurrence,
+		Visibility:         r.Visibility,
+		Passphrase:         r.Passphrase,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating wishlist"})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"wishlist": wishlist})
 }
 
 func DeleteWishlist(c *gin.Context) {
-	var wishlistService = service.NewWishlistService()
 	auth, exist := c.Get("telegram_auth")
 	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		abortWithError(c, apperr.ErrUnauthorized)
 		return
 	}
 
-	listId := c.Param("listId")
-	shareCode, err := uuid.Parse(listId)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
-		return
-	}
-	// Проверяем, есть ли у пользователя доступ к этому списку желаний
-	hasAccess := wishlistService.CheckAccess(auth.(*middleware.TelegramAuthData).User.ID, shareCode)
-	if !hasAccess {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	// удалить список может только владелец
+	wishlist, _, ok := listAccess(c, auth.(*middleware.TelegramAuthData).User.ID, models.RoleOwner)
+	if !ok {
 		return
 	}
 
-	err = wishlistService.Delete(shareCode)
+	wishlistService := service.NewWishlistService().WithContext(c.Request.Context())
+	err := wishlistService.Delete(wishlist.ShareCode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting wishlist"})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "wishlist deleted"})
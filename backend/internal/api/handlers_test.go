@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/db"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testBotToken = "123456:test"
+	testUserID   = 1001
+	guestToken   = "guest-token"
+)
+
+var (
+	ownList   = uuid.MustParse("00000000-0000-0000-0000-00000000000a") // список пользователя
+	otherList = uuid.MustParse("00000000-0000-0000-0000-00000000000b") // второй список пользователя, цель переноса
+	guestList = uuid.MustParse("00000000-0000-0000-0000-00000000000c") // чужой список, гость открывает его по ссылке
+	hidden    = uuid.MustParse("00000000-0000-0000-0000-00000000000d") // чужой список, пользователь в нем не участник
+	noList    = uuid.MustParse("00000000-0000-0000-0000-0000000000ff")
+)
+
+// fakeDB - драйвер database/sql без сервера: отдает строки таблиц по значению любого параметра запроса,
+// на остальные запросы отвечает пустой выборкой, изменения не затрагивают ни одной строки
+type fakeDB struct {
+	tables map[string]fakeTable
+}
+
+type fakeTable struct {
+	columns []string
+	rows    map[string][]driver.Value
+}
+
+func (d *fakeDB) Open(string) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	for name, table := range c.db.tables {
+		if !strings.Contains(query, `FROM "`+name+`"`) {
+			continue
+		}
+		for _, arg := range args {
+			if row, ok := table.rows[fmt.Sprint(arg.Value)]; ok {
+				return &fakeRows{columns: table.columns, rows: [][]driver.Value{row}}, nil
+			}
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	config.Config = &config.AppConfigStruct{}
+	config.Config.Telegram.BotToken = testBotToken
+
+	listColumns := []string{"id", "share_code", "owner_id", "name", "visibility"}
+	sql.Register("fakedb", &fakeDB{tables: map[string]fakeTable{
+		"wish_lists": {columns: listColumns, rows: map[string][]driver.Value{
+			ownList.String():   {int64(1), ownList.String(), int64(testUserID), "own", "link"},
+			otherList.String(): {int64(2), otherList.String(), int64(testUserID), "other", "link"},
+			"3":                {int64(3), guestList.String(), int64(2002), "guest", "link"}, // подгрузка списка по ссылке
+			hidden.String():    {int64(4), hidden.String(), int64(2002), "hidden", "link"},
+		}},
+		"share_tokens": {columns: []string{"id", "wish_list_id", "token"}, rows: map[string][]driver.Value{
+			guestToken: {int64(1), int64(3), guestToken},
+		}},
+	}})
+	conn, err := sql.Open("fakedb", "")
+	if err != nil {
+		panic(err)
+	}
+	db.ORM, err = gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// initData подписывает данные мини-приложения так же, как Telegram
+func initData(userID int64) string {
+	user, _ := json.Marshal(map[string]any{"id": userID, "first_name": "Test"})
+	values := url.Values{
+		"auth_date": {strconv.FormatInt(time.Now().Unix(), 10)},
+		"user":      {string(user)},
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+values.Get(key))
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(testBotToken))
+	sign := hmac.New(sha256.New, secret.Sum(nil))
+	sign.Write([]byte(strings.Join(parts, "\n")))
+	values.Set("hash", hex.EncodeToString(sign.Sum(nil)))
+	return values.Encode()
+}
+
+func newTestRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.Errors())
+	Register(router)
+	return router
+}
+
+func TestMissingListOrWishReturnsNotFound(t *testing.T) {
+	router := newTestRouter()
+	auth := "tma " + initData(testUserID)
+
+	missing := "/api/v1/list/" + noList.String()
+	own := "/api/v1/list/" + ownList.String()
+	guest := "/api/v1/list/" + guestToken
+	move := `{"wish_ids": [1], "target_list": "` + otherList.String() + `"}`
+
+	cases := []struct {
+		method, path, body, code string
+	}{
+		{"GET", missing, "", "wishlist_not_found"},
+		{"GET", "/api/v1/list/unknown-token", "", "wishlist_not_found"},
+		{"PATCH", missing, `{"name": "list"}`, "wishlist_not_found"},
+		{"DELETE", missing, "", "wishlist_not_found"},
+		{"POST", missing + "/unlock", `{"passphrase": "secret"}`, "wishlist_not_found"},
+
+		{"GET", missing + "/wishes", "", "wishlist_not_found"},
+		{"POST", missing + "/wishes", `{"name": "wish"}`, "wishlist_not_found"},
+		{"GET", missing + "/wishes/1", "", "wishlist_not_found"},
+		{"PATCH", missing + "/wishes/1", `{"name": "wish"}`, "wishlist_not_found"},
+		{"DELETE", missing + "/wishes/1", "", "wishlist_not_found"},
+		{"POST", missing + "/wishes/1/move", `{"before": 2}`, "wishlist_not_found"},
+		{"POST", missing + "/wishes/transfer", move, "wishlist_not_found"},
+		{"POST", missing + "/wishes/copy", move, "wishlist_not_found"},
+		{"GET", own + "/wishes/1", "", "wish_item_not_found"},
+		{"PATCH", own + "/wishes/1", `{"name": "wish"}`, "wish_item_not_found"},
+		{"DELETE", own + "/wishes/1", "", "wish_item_not_found"},
+		{"POST", own + "/wishes/1/move", `{"before": 2}`, "wish_item_not_found"},
+		{"POST", own + "/wishes/transfer", move, "wish_item_not_found"},
+		{"POST", own + "/wishes/copy", move, "wish_item_not_found"},
+		{"POST", own + "/wishes/transfer", `{"wish_ids": [1], "target_list": "` + noList.String() + `"}`, "wishlist_not_found"},
+		{"POST", own + "/wishes/transfer", `{"wish_ids": [1], "target_list": "` + hidden.String() + `"}`, "wishlist_not_found"},
+		{"POST", own + "/wishes/copy", `{"wish_ids": [1], "target_list": "` + hidden.String() + `"}`, "wishlist_not_found"},
+
+		{"POST", missing + "/wishes/1/reservation", "", "wishlist_not_found"},
+		{"DELETE", missing + "/wishes/1/reservation", "", "wishlist_not_found"},
+		{"POST", guest + "/wishes/1/reservation", "", "wish_item_not_found"},
+		{"DELETE", guest + "/wishes/1/reservation", "", "wish_item_not_found"},
+		{"POST", missing + "/wishes/1/reservation/renew", "", "wishlist_not_found"},
+		{"POST", guest + "/wishes/1/reservation/renew", "", "wish_item_not_found"},
+
+		{"POST", missing + "/wishes/1/contribution", `{"amount": 100}`, "wishlist_not_found"},
+		{"DELETE", missing + "/wishes/1/contribution", "", "wishlist_not_found"},
+		{"POST", guest + "/wishes/1/contribution", `{"amount": 100}`, "wish_item_not_found"},
+		{"DELETE", guest + "/wishes/1/contribution", "", "wish_item_not_found"},
+
+		{"GET", missing + "/share-tokens", "", "wishlist_not_found"},
+		{"POST", missing + "/share-tokens", `{}`, "wishlist_not_found"},
+		{"POST", missing + "/share-tokens/1/rotate", "", "wishlist_not_found"},
+		{"DELETE", missing + "/share-tokens/1", "", "wishlist_not_found"},
+		{"POST", own + "/share-tokens/1/rotate", "", "share_link_not_found"},
+		{"DELETE", own + "/share-tokens/1", "", "share_link_not_found"},
+
+		{"GET", missing + "/members", "", "wishlist_not_found"},
+		{"PATCH", missing + "/members/2", `{"role": "viewer"}`, "wishlist_not_found"},
+		{"DELETE", missing + "/members/2", "", "wishlist_not_found"},
+		{"GET", missing + "/invites", "", "wishlist_not_found"},
+		{"POST", missing + "/invites", `{"role": "viewer"}`, "wishlist_not_found"},
+		{"DELETE", missing + "/invites/" + uuid.NewString(), "", "wishlist_not_found"},
+		{"PATCH", own + "/members/2", `{"role": "viewer"}`, "member_not_found"},
+		{"DELETE", own + "/members/2", "", "member_not_found"},
+		{"DELETE", own + "/invites/" + uuid.NewString(), "", "invite_not_found"},
+		{"POST", "/api/v1/invites/" + uuid.NewString() + "/accept", "", "invite_not_found"},
+
+		{"GET", missing + "/allowed", "", "wishlist_not_found"},
+		{"POST", missing + "/allowed", `{"telegram_id": 2}`, "wishlist_not_found"},
+		{"DELETE", missing + "/allowed/2", "", "wishlist_not_found"},
+		{"DELETE", own + "/allowed/2", "", "allowed_user_not_found"},
+
+		{"PATCH", "/api/v1/account/tags/1", `{"name": "tag"}`, "tag_not_found"},
+		{"DELETE", "/api/v1/account/tags/1", "", "tag_not_found"},
+		{"GET", missing + "/tags", "", "wishlist_not_found"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", auth)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404; body: %s", w.Code, w.Body)
+			}
+			var resp struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("body is not an error envelope: %s", w.Body)
+			}
+			if resp.Error.Code != tc.code {
+				t.Fatalf("code = %q, want %q", resp.Error.Code, tc.code)
+			}
+		})
+	}
+}
+
+func TestCalendarFeedUnknownToken(t *testing.T) {
+	router := newTestRouter()
+
+	for _, path := range []string{"/calendar/" + uuid.NewString() + ".ics", "/calendar/not-a-token.ics"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status = %d, want 404; body: %s", path, w.Code, w.Body)
+		}
+	}
+}
+
+func TestUnknownRouteUsesErrorEnvelope(t *testing.T) {
+	router := newTestRouter()
+
+	cases := []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{"GET", "/api/v2/list", http.StatusNotFound, "route_not_found"},
+		{"PUT", "/api/v1/list", http.StatusMethodNotAllowed, "method_not_allowed"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.status || !strings.Contains(w.Body.String(), `"code":"`+tc.code+`"`) {
+			t.Errorf("%s %s: got %d %s, want %d %s", tc.method, tc.path, w.Code, w.Body, tc.status, tc.code)
+		}
+	}
+}
@@ -1,20 +1,35 @@
 package middleware
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"wishlist-go/internal/apperr"
 	"wishlist-go/internal/config"
+	"wishlist-go/internal/metrics"
 	"wishlist-go/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("wishlist-go/internal/api/middleware")
+
+var (
+	errWrongAuthHeader         = apperr.New(apperr.KindUnauthorized, "wrong_auth_header", "wrong auth header")
+	errInvalidTokenFormat      = apperr.New(apperr.KindUnauthorized, "invalid_token_format", "invalid token format")
+	errInvalidUserData         = apperr.New(apperr.KindUnauthorized, "invalid_user_data", "invalid user data")
+	errInvalidTelegramAuthData = apperr.New(apperr.KindUnauthorized, "invalid_telegram_auth_data", "invalid Telegram auth data")
+	errInvalidTelegramUserID   = apperr.New(apperr.KindUnauthorized, "invalid_telegram_user_id", "invalid Telegram user ID")
 )
 
 type TelegramUser struct {
@@ -77,24 +92,24 @@ func TelegramAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(401, gin.H{"error": "unauthorized"})
-			c.Abort()
+			metrics.AuthFailures.WithLabelValues("missing_header").Inc()
+			AbortWithError(c, apperr.ErrUnauthorized)
 			return
 		}
 
 		re := regexp.MustCompile(`^tma (.+)$`)
 		matches := re.FindStringSubmatch(authHeader)
 		if matches == nil || len(matches) < 2 {
-			c.JSON(401, gin.H{"error": "wrong auth header"})
-			c.Abort()
+			metrics.AuthFailures.WithLabelValues("wrong_scheme").Inc()
+			AbortWithError(c, errWrongAuthHeader)
 			return
 		}
 
 		rawAuthData := matches[1]
 		values, err := url.ParseQuery(rawAuthData)
 		if err != nil {
-			c.JSON(401, gin.H{"error": "invalid token format"})
-			c.Abort()
+			metrics.AuthFailures.WithLabelValues("invalid_format").Inc()
+			AbortWithError(c, errInvalidTokenFormat)
 			return
 		}
 		// извлекаем данные
@@ -111,35 +126,44 @@ func TelegramAuthMiddleware() gin.HandlerFunc {
 		// парсим user (это JSON в URL-encoded формате)
 		if userStr := values.Get("user"); userStr != "" {
 			if err := json.Unmarshal([]byte(userStr), &authData.User); err != nil {
-				c.JSON(401, gin.H{"error": "invalid user data"})
-				c.Abort()
+				metrics.AuthFailures.WithLabelValues("invalid_user").Inc()
+				AbortWithError(c, errInvalidUserData)
 				return
 			}
 		}
-		if !validateTelegramAuthData(rawAuthData, authData.Hash) {
-			c.JSON(401, gin.H{"error": "invalid Telegram auth data"})
-			c.Abort()
+		_, span := tracer.Start(c.Request.Context(), "TelegramAuth.validate")
+		valid := validateTelegramAuthData(rawAuthData, authData.Hash)
+		span.End()
+		if !valid {
+			metrics.AuthFailures.WithLabelValues("invalid_signature").Inc()
+			AbortWithError(c, errInvalidTelegramAuthData)
+			return
+		}
+		// без пользователя не к чему привязать аккаунт и списки
+		if authData.User.ID == 0 {
+			metrics.AuthFailures.WithLabelValues("missing_user").Inc()
+			AbortWithError(c, errInvalidTelegramUserID)
 			return
 		}
 
 		// добавляем данные в контекст
 		c.Set("telegram_auth", &authData)
+		setLogger(c, Logger(c).With("user_id", authData.User.ID))
+		logger := Logger(c)
+		// аккаунт создается уже после ответа, поэтому контекст запроса не должен его отменять
+		accountCtx := context.WithoutCancel(c.Request.Context())
 
 		// асинхронно создаем аккаунт, если его нет
 		go func(authData TelegramAuthData) {
-			account := service.NewAccountService()
+			account := service.NewAccountService().WithContext(accountCtx)
 			_, err := account.Get(authData.User.ID)
-			if err != nil {
-				if authData.User.ID == 0 {
-					c.JSON(401, gin.H{"error": "invalid Telegram user ID"})
-					c.Abort()
-				} else {
-					_, err := account.Create(authData.User.ID)
-					if err != nil {
-						fmt.Println("Failed to create account:", err)
-					}
+			switch {
+			case errors.Is(err, service.ErrAccountNotFound):
+				if _, err := account.Create(authData.User.ID); err != nil {
+					logger.Error("failed to create account", "error", err)
 				}
-
+			case err != nil:
+				logger.Error("failed to fetch account", "error", err)
 			}
 		}(authData)
 		c.Next()
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"wishlist-go/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorBody - единый формат ошибки во всех ответах API
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AbortWithError прерывает обработку запроса; ответ сформирует Errors
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// Errors превращает последнюю ошибку из c.Errors в ответ {"error": {...}} со статусом по ее виду
+// и сообщением на языке пользователя Telegram. Неизвестные ошибки отдаются как internal_error
+// без подробностей; причина остается в логе и Sentry.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		appErr := apperr.From(c.Errors.Last().Err)
+		if appErr.Kind == apperr.KindInternal && appErr.Err != nil {
+			Logger(c).Error("request failed", "error", appErr.Err)
+		}
+		c.JSON(appErr.Status(), gin.H{"error": errorBody(c, appErr)})
+	}
+}
+
+func errorBody(c *gin.Context, appErr *apperr.Error) ErrorBody {
+	return ErrorBody{
+		Code:      appErr.Code,
+		Message:   apperr.Localize(appErr, languageCode(c)),
+		Details:   appErr.Details,
+		RequestID: c.GetString("request_id"),
+	}
+}
+
+func languageCode(c *gin.Context) string {
+	if auth, exist := c.Get("telegram_auth"); exist {
+		return auth.(*TelegramAuthData).User.LanguageCode
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"wishlist-go/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics считает запросы и время ответа по шаблону маршрута, а не по пути,
+// чтобы идентификаторы списков не раздували число серий
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"wishlist-go/internal/apperr"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+)
+
+// заголовки, которые нельзя отправлять в Sentry: initData Telegram и доступ к защищенным спискам
+var sentryHiddenHeaders = []string{"Authorization", "X-List-Grant", "Cookie"}
+
+// Sentry перехватывает панику и отправляет ее, а также все ответы 5xx, в Sentry
+// вместе с маршрутом, request_id и пользователем Telegram. Паника превращается в ответ 500.
+func Sentry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hub := sentry.CurrentHub().Clone()
+		ctx := sentry.SetHubOnContext(c.Request.Context(), hub)
+		c.Request = c.Request.WithContext(ctx)
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// обрыв соединения клиентом штатно завершает обработчик через панику
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errorBody(c, apperr.ErrInternal)})
+			fillSentryScope(c, hub)
+			hub.RecoverWithContext(ctx, recovered)
+			Logger(c).Error("panic recovered", "panic", fmt.Sprint(recovered))
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() < http.StatusInternalServerError {
+			return
+		}
+		fillSentryScope(c, hub)
+		if len(c.Errors) == 0 {
+			hub.CaptureMessage(fmt.Sprintf("%d %s %s", c.Writer.Status(), c.Request.Method, c.FullPath()))
+			return
+		}
+		for _, err := range c.Errors {
+			hub.CaptureException(err.Err)
+		}
+	}
+}
+
+func fillSentryScope(c *gin.Context, hub *sentry.Hub) {
+	scope := hub.Scope()
+	scope.SetTag("request_id", c.GetString("request_id"))
+	scope.SetTag("route", c.FullPath())
+	scope.SetTag("status", strconv.Itoa(c.Writer.Status()))
+	if auth, exist := c.Get("telegram_auth"); exist {
+		user := auth.(*TelegramAuthData).User
+		scope.SetUser(sentry.User{ID: strconv.FormatInt(user.ID, 10), Username: user.Username})
+	}
+
+	// в пути лежат ссылки на списки, поэтому вместо него отправляется шаблон маршрута
+	request := sentry.NewRequest(c.Request)
+	request.URL = c.FullPath()
+	request.QueryString = ""
+	for _, header := range sentryHiddenHeaders {
+		delete(request.Headers, header)
+	}
+	scope.AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+		event.Request = request
+		return event
+	})
+}
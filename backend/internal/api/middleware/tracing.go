@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// служебные маршруты опрашиваются постоянно и только засоряли бы трассы
+var untracedRoutes = map[string]bool{"/metrics": true, "/healthz": true, "/readyz": true}
+
+// Tracing открывает корневой спан запроса; спаны сервисов и запросов к базе становятся его потомками
+func Tracing(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		return !untracedRoutes[c.FullPath()]
+	}))
+}
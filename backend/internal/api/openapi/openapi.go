@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path - адрес документа; лежит рядом с API, но без авторизации Telegram
+const Path = "/api/v1/openapi.json"
+
+// Spec - описание API в формате OpenAPI 3.1; при изменении маршрутов в public_router.go правится вместе с ними
+//
+//go:embed openapi.json
+var Spec []byte
+
+func Handler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", Spec)
+}
+
+// Verify сверяет зарегистрированные маршруты с документом: каждому маршруту нужна операция
+// в спецификации и наоборот. OPTIONS (CORS) и служебные /metrics не описываются.
+func Verify(routes gin.RoutesInfo) error {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		return fmt.Errorf("parse openapi.json: %w", err)
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var problems []string
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if route.Method == http.MethodOptions || route.Path == "/metrics" {
+			continue
+		}
+		key := route.Method + " " + specPath(route.Path)
+		registered[key] = true
+		if !documented[key] {
+			problems = append(problems, "not in spec: "+key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			problems = append(problems, "no such route: "+key)
+		}
+	}
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("openapi.json is out of sync with the router:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// specPath переводит шаблон gin (/list/:listId) в шаблон OpenAPI (/list/{listId})
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,42 @@
+package openapi_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"wishlist-go/internal/api"
+	"wishlist-go/internal/api/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// сгенерированные типы лежат во фронтенде; в образе backend его нет, там сверка пропускается
+const schemaTS = "../../../../frontend/api/schema.ts"
+
+func TestSpecMatchesRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.Register(router)
+	if err := openapi.Verify(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFrontendTypesUpToDate(t *testing.T) {
+	want, err := openapi.TypeScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(schemaTS)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("frontend is not checked out")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("frontend/api/schema.ts is stale, run make openapi-types")
+	}
+}
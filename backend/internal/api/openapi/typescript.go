@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ordered - объект JSON с сохранением порядка ключей: типы выводятся в том же порядке, что и в документе
+type ordered[T any] []entry[T]
+
+type entry[T any] struct {
+	Key   string
+	Value T
+}
+
+func (o *ordered[T]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value T
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("%v: %w", key, err)
+		}
+		*o = append(*o, entry[T]{Key: key.(string), Value: value})
+	}
+	return nil
+}
+
+// schema - подмножество JSON Schema, которое используется в openapi.json
+type schema struct {
+	Ref                  string          `json:"$ref"`
+	Type                 string          `json:"type"`
+	Description          string          `json:"description"`
+	Enum                 []any           `json:"enum"`
+	Items                *schema         `json:"items"`
+	Properties           ordered[schema] `json:"properties"`
+	Required             []string        `json:"required"`
+	AdditionalProperties *schema         `json:"additionalProperties"`
+	OneOf                []schema        `json:"oneOf"`
+}
+
+type content map[string]struct {
+	Schema *schema `json:"schema"`
+}
+
+type parameter struct {
+	Ref string `json:"$ref"`
+	In  string `json:"in"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody struct {
+		Content content `json:"content"`
+	} `json:"requestBody"`
+	Responses ordered[struct {
+		Content content `json:"content"`
+	}] `json:"responses"`
+}
+
+var (
+	identifier    = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	pathParameter = regexp.MustCompile(`\{[^}]+\}`)
+)
+
+// apiPrefix - общая часть путей, которые фронтенд вызывает через backendAPI
+const apiPrefix = "/api/v1/"
+
+// apiOperation - строка карты операций: по ней фронтенд проверяет путь и тела каждого вызова
+type apiOperation struct {
+	name, method, path, request, response string
+}
+
+var methods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// TypeScript выводит типы для фронтенда: схемы из components, тела запросов и ответов
+// каждой операции (<OperationId>Request, <OperationId>Response) и карту операций /api/v1/
+// (operations, Operations). Результат лежит в frontend/api/schema.ts.
+func TypeScript() ([]byte, error) {
+	var doc struct {
+		Paths      ordered[ordered[json.RawMessage]] `json:"paths"`
+		Components struct {
+			Schemas    ordered[schema]      `json:"schemas"`
+			Parameters map[string]parameter `json:"parameters"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi.json: %w", err)
+	}
+
+	var out strings.Builder
+	out.WriteString("// Сгенерировано из backend/internal/api/openapi/openapi.json командой make openapi-types, не править вручную\n")
+
+	for _, named := range doc.Components.Schemas {
+		out.WriteString("\n")
+		writeDoc(&out, "", named.Value.Description)
+		if named.Value.Ref == "" && len(named.Value.Properties) > 0 {
+			fmt.Fprintf(&out, "export interface %s %s\n", named.Key, tsType(named.Value, ""))
+		} else {
+			fmt.Fprintf(&out, "export type %s = %s;\n", named.Key, tsType(named.Value, ""))
+		}
+	}
+
+	var operations []apiOperation
+	for _, path := range doc.Paths {
+		for _, op := range path.Value {
+			method := strings.ToUpper(op.Key)
+			if !slices.Contains(methods, method) {
+				continue
+			}
+			var operation operation
+			if err := json.Unmarshal(op.Value, &operation); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path.Key, err)
+			}
+			if operation.OperationID == "" {
+				return nil, fmt.Errorf("%s %s: operationId is required", method, path.Key)
+			}
+			name := strings.ToUpper(operation.OperationID[:1]) + operation.OperationID[1:]
+			api := apiOperation{name: operation.OperationID, method: method, request: "null", response: "void"}
+
+			if body := jsonSchema(operation.RequestBody.Content); body != nil {
+				fmt.Fprintf(&out, "\n/** %s %s */\nexport type %sRequest = %s;\n", method, path.Key, name, tsType(*body, ""))
+				api.request = name + "Request"
+			}
+			for _, response := range operation.Responses {
+				if !strings.HasPrefix(response.Key, "2") {
+					continue
+				}
+				if body := jsonSchema(response.Value.Content); body != nil {
+					fmt.Fprintf(&out, "\n/** %s %s */\nexport type %sResponse = %s;\n", method, path.Key, name, tsType(*body, ""))
+					api.response = name + "Response"
+					break
+				}
+			}
+
+			relative, ok := strings.CutPrefix(path.Key, apiPrefix)
+			if !ok || path.Key == Path {
+				continue
+			}
+			// параметры пути - любая строка; при параметрах запроса к пути можно добавить ?query
+			template := pathParameter.ReplaceAllLiteralString(relative, "${string}")
+			api.path = "`" + template + "`"
+			for _, param := range operation.Parameters {
+				if param.Ref != "" {
+					param = doc.Components.Parameters[param.Ref[strings.LastIndex(param.Ref, "/")+1:]]
+				}
+				if param.In == "query" {
+					api.path += " | `" + template + "?${string}`"
+					break
+				}
+			}
+			operations = append(operations, api)
+		}
+	}
+
+	out.WriteString("\n/** HTTP-метод каждой операции " + apiPrefix + " */\nexport const operations = {\n")
+	for _, api := range operations {
+		fmt.Fprintf(&out, "    %s: %q,\n", api.name, api.method)
+	}
+	out.WriteString("} as const;\n")
+	out.WriteString("\n/** Путь относительно " + apiPrefix + ", тела запроса и ответа каждой операции */\nexport interface Operations {\n")
+	for _, api := range operations {
+		fmt.Fprintf(&out, "    %s: {\n        path: %s;\n        request: %s;\n        response: %s;\n    };\n",
+			api.name, api.path, api.request, api.response)
+	}
+	out.WriteString("}\n")
+	return []byte(out.String()), nil
+}
+
+func jsonSchema(c content) *schema {
+	if media, ok := c["application/json"]; ok {
+		return media.Schema
+	}
+	return nil
+}
+
+func writeDoc(out *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	fmt.Fprintf(out, "%s/** %s */\n", indent, strings.ReplaceAll(description, "*/", "*\\/"))
+}
+
+// tsType переводит схему в выражение типа; indent - отступ строки, с которой начинается тип
+func tsType(s schema, indent string) string {
+	switch {
+	case s.Ref != "":
+		return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	case len(s.Enum) > 0:
+		literals := make([]string, 0, len(s.Enum))
+		for _, value := range s.Enum {
+			literal, _ := json.Marshal(value)
+			literals = append(literals, string(literal))
+		}
+		return strings.Join(literals, " | ")
+	case len(s.OneOf) > 0 && (s.OneOf[0].Type != "" || s.OneOf[0].Ref != ""):
+		variants := make([]string, 0, len(s.OneOf))
+		for _, variant := range s.OneOf {
+			variants = append(variants, tsType(variant, indent))
+		}
+		return strings.Join(variants, " | ")
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		if s.Items == nil {
+			return "unknown[]"
+		}
+		item := tsType(*s.Items, indent)
+		if strings.Contains(item, " | ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(s.Properties) == 0 {
+			if s.AdditionalProperties != nil {
+				return "Record<string, " + tsType(*s.AdditionalProperties, indent) + ">"
+			}
+			return "Record<string, unknown>"
+		}
+	}
+	if len(s.Properties) == 0 {
+		return "unknown"
+	}
+
+	// одни варианты oneOf с required (ровно одно из полей) выразить в интерфейсе нельзя, поля остаются необязательными
+	var out strings.Builder
+	out.WriteString("{\n")
+	inner := indent + "    "
+	for _, property := range s.Properties {
+		writeDoc(&out, inner, property.Value.Description)
+		name := property.Key
+		if !identifier.MatchString(name) {
+			name = fmt.Sprintf("%q", name)
+		}
+		if !slices.Contains(s.Required, property.Key) {
+			name += "?"
+		}
+		fmt.Fprintf(&out, "%s%s: %s;\n", inner, name, tsType(property.Value, inner))
+	}
+	out.WriteString(indent + "}")
+	return out.String()
+}
@@ -3,29 +3,101 @@ package api
 import (
 	"wishlist-go/internal/api/handlers"
 	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/api/openapi"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Register подключает все группы маршрутов; используется сервером и проверкой openapi.json
+func Register(router *gin.Engine) {
+	HealthApi(router)
+	DocsApi(router)
+	PublicApi(router)
+	CalendarApi(router)
+
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handlers.NoRoute)
+	router.NoMethod(handlers.NoMethod)
+}
+
 func PublicApi(router *gin.Engine) *gin.RouterGroup {
 	publicEndpoints := router.Group("/api/v1/")
 	publicEndpoints.Use(middleware.CorsMiddleware())
 	publicEndpoints.Use(middleware.TelegramAuthMiddleware())
 	{
-		publicEndpoints.OPTIONS("*path", handlers.OptionsHandler)                      // Получить все списки
-		publicEndpoints.GET("list", handlers.GetWishlists)                             // Получить все списки
-		publicEndpoints.POST("list", handlers.CreateWishlist)                          // Создать список
-		publicEndpoints.PATCH("list/:listId", handlers.UpdateWishlist)                 // Обновить список
-		publicEndpoints.DELETE("list/:listId", handlers.DeleteWishlist)                // Удалить список
-		publicEndpoints.GET("list/:listId/wishes", handlers.GetWishItems)              // Получить все желания в списке
-		publicEndpoints.POST("list/:listId/wishes", handlers.CreateWishItem)           // Добавить желание в список
-		publicEndpoints.GET("list/:listId/wishes/:wishId", handlers.GetWishItem)       // Получить конкретное желание
-		publicEndpoints.PATCH("list/:listId/wishes/:wishId", handlers.UpdateWishItem)  // Обновить конкретное желание
-		publicEndpoints.DELETE("list/:listId/wishes/:wishId", handlers.DeleteWishItem) // Удалить конкретное желание
-
-		publicEndpoints.DELETE("account", handlers.DeleteAccount) // Удалить аккаунт и все списки
+		publicEndpoints.OPTIONS("*path", handlers.OptionsHandler)                       // Получить все списки
+		publicEndpoints.GET("list", handlers.GetWishlists)                              // Получить все списки
+		publicEndpoints.POST("list", handlers.CreateWishlist)                           // Создать список
+		publicEndpoints.GET("list/:listId", handlers.GetWishlist)                       // Получить список с обратным отсчетом до повода
+		publicEndpoints.PATCH("list/:listId", handlers.UpdateWishlist)                  // Обновить список
+		publicEndpoints.DELETE("list/:listId", handlers.DeleteWishlist)                 // Удалить список
+		publicEndpoints.GET("list/:listId/wishes", handlers.GetWishItems)               // Получить все желания в списке
+		publicEndpoints.POST("list/:listId/wishes", handlers.CreateWishItem)            // Добавить желание в список
+		publicEndpoints.GET("list/:listId/wishes/:wishId", handlers.GetWishItem)        // Получить конкретное желание
+		publicEndpoints.PATCH("list/:listId/wishes/:wishId", handlers.UpdateWishItem)   // Обновить конкретное желание
+		publicEndpoints.DELETE("list/:listId/wishes/:wishId", handlers.DeleteWishItem)  // Удалить конкретное желание
+		publicEndpoints.POST("list/:listId/wishes/:wishId/move", handlers.MoveWishItem) // Переставить желание в списке
+
+		publicEndpoints.POST("list/:listId/wishes/transfer", handlers.TransferWishItems) // Перенести желания в другой список
+		publicEndpoints.POST("list/:listId/wishes/copy", handlers.CopyWishItems)         // Скопировать желания в другой список
+		publicEndpoints.GET("search/wishes", handlers.SearchWishItems)                   // Поиск желаний по всем своим спискам
+
+		publicEndpoints.POST("list/:listId/wishes/:wishId/reservation", handlers.ReserveWishItem)         // Зарезервировать желание (целиком или часть)
+		publicEndpoints.DELETE("list/:listId/wishes/:wishId/reservation", handlers.CancelReservation)     // Снять свой резерв
+		publicEndpoints.POST("list/:listId/wishes/:wishId/reservation/renew", handlers.RenewReservation)  // Продлить свой резерв
+		publicEndpoints.POST("list/:listId/wishes/:wishId/contribution", handlers.PledgeContribution)     // Внести вклад в совместный подарок
+		publicEndpoints.DELETE("list/:listId/wishes/:wishId/contribution", handlers.WithdrawContribution) // Отозвать свой вклад
+
+		publicEndpoints.GET("list/:listId/members", handlers.GetMembers)                 // Участники совместного списка
+		publicEndpoints.PATCH("list/:listId/members/:accountId", handlers.UpdateMember)  // Сменить роль участника
+		publicEndpoints.DELETE("list/:listId/members/:accountId", handlers.RemoveMember) // Исключить участника или выйти из списка
+		publicEndpoints.GET("list/:listId/invites", handlers.GetInvites)                 // Действующие приглашения
+		publicEndpoints.POST("list/:listId/invites", handlers.CreateInvite)              // Создать ссылку-приглашение с ролью
+		publicEndpoints.DELETE("list/:listId/invites/:code", handlers.RevokeInvite)      // Отозвать приглашение
+		publicEndpoints.POST("invites/:code/accept", handlers.AcceptInvite)              // Принять приглашение
+
+		publicEndpoints.GET("list/:listId/share-tokens", handlers.GetShareTokens)                    // Ссылки для гостей
+		publicEndpoints.POST("list/:listId/share-tokens", handlers.CreateShareToken)                 // Выпустить ссылку (метка, срок)
+		publicEndpoints.POST("list/:listId/share-tokens/:tokenId/rotate", handlers.RotateShareToken) // Заменить ссылку новой
+		publicEndpoints.DELETE("list/:listId/share-tokens/:tokenId", handlers.RevokeShareToken)      // Отозвать ссылку
+
+		publicEndpoints.POST("list/:listId/unlock", handlers.UnlockWishlist) // Ввести кодовую фразу и получить временный доступ
+
+		publicEndpoints.GET("list/:listId/allowed", handlers.GetAllowedUsers)             // Белый список для видимости invite
+		publicEndpoints.POST("list/:listId/allowed", handlers.AllowUser)                  // Открыть список пользователю Telegram
+		publicEndpoints.DELETE("list/:listId/allowed/:telegramId", handlers.DisallowUser) // Закрыть список для пользователя
+		publicEndpoints.GET("users/:userId/lists", handlers.GetPublicWishlists)           // Публичный профиль: списки с видимостью public
+
+		publicEndpoints.DELETE("account", handlers.DeleteAccount)                    // Удалить аккаунт и все списки
+		publicEndpoints.GET("account/calendar", handlers.GetCalendarLink)            // Секретная ссылка на iCal-ленту поводов
+		publicEndpoints.POST("account/calendar/rotate", handlers.RotateCalendarLink) // Перевыпустить ссылку на ленту
+		publicEndpoints.GET("account/tags", handlers.GetTags)                        // Метки для группировки желаний
+		publicEndpoints.POST("account/tags", handlers.CreateTag)                     // Создать метку
+		publicEndpoints.PATCH("account/tags/:tagId", handlers.RenameTag)             // Переименовать метку
+		publicEndpoints.DELETE("account/tags/:tagId", handlers.DeleteTag)            // Удалить метку и снять ее с желаний
+		publicEndpoints.GET("list/:listId/tags", handlers.GetListTags)               // Метки владельца списка для редакторов
 
 		publicEndpoints.GET("health", handlers.HealthCheck) // Проверка здоровья сервиса
 	}
 	return publicEndpoints
 }
+
+// CalendarApi - ленты для календарных приложений; они не умеют передавать initData, доступ по секрету в ссылке
+func CalendarApi(router *gin.Engine) *gin.RouterGroup {
+	calendarEndpoints := router.Group("/calendar/")
+	{
+		calendarEndpoints.GET(":token", handlers.CalendarFeed) // iCal-лента: /calendar/<token>.ics
+	}
+	return calendarEndpoints
+}
+
+// HealthApi - пробы для оркестратора, без авторизации и CORS
+func HealthApi(router *gin.Engine) {
+	router.GET("/healthz", handlers.Liveness) // Процесс жив
+	router.GET("/readyz", handlers.Readiness) // База, миграции и воркер в порядке
+}
+
+// DocsApi - описание API для клиентов и генераторов типов, без авторизации
+func DocsApi(router *gin.Engine) {
+	router.GET(openapi.Path, openapi.Handler) // OpenAPI 3.1
+}
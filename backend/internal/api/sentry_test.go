@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/metrics"
+	"wishlist-go/internal/monitoring"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSentryCapturesPanicsAndServerErrors(t *testing.T) {
+	transport := monitoring.NewMemoryTransport()
+	if err := monitoring.InitSentry(&config.AppConfigStruct{}, transport); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sentry.CurrentHub().BindClient(nil) })
+
+	// порядок middleware как в server.go
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Sentry())
+	router.Use(middleware.Errors())
+	Register(router)
+	failing := router.Group("/test/", middleware.TelegramAuthMiddleware())
+	failing.GET("panic/:listId", func(*gin.Context) { panic("boom") })
+	failing.GET("fail/:listId", func(c *gin.Context) { _ = c.Error(errors.New("database is down")) })
+
+	secrets := []string{"secret-link", "secret-query", "secret-grant", "secret-cookie", "192.0.2.1"}
+	cases := []struct{ path, route string }{
+		{"/test/panic/secret-link?passphrase=secret-query", "/test/panic/:listId"},
+		{"/test/fail/secret-link?passphrase=secret-query", "/test/fail/:listId"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.route, func(t *testing.T) {
+			transport.Reset()
+			served := metrics.HTTPRequests.WithLabelValues("GET", tc.route, "500")
+			before := testutil.ToFloat64(served)
+			auth := "tma " + initData(testUserID)
+			req := httptest.NewRequest("GET", tc.path, nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("Authorization", auth)
+			req.Header.Set("X-List-Grant", "secret-grant")
+			req.Header.Set("Cookie", "session=secret-cookie")
+			req.Header.Set(middleware.RequestIDHeader, "req-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), `"code":"internal_error"`) {
+				t.Fatalf("got %d %s, want 500 internal_error", w.Code, w.Body)
+			}
+			if got := testutil.ToFloat64(served) - before; got != 1 {
+				t.Errorf("http_requests for 500 grew by %v, want 1", got)
+			}
+			events := transport.Events()
+			if len(events) != 1 {
+				t.Fatalf("captured %d events, want 1", len(events))
+			}
+			event := events[0]
+			if event.Tags["request_id"] != "req-1" || event.Tags["route"] != tc.route {
+				t.Errorf("tags = %v, want request_id req-1 and route %s", event.Tags, tc.route)
+			}
+			if event.User.ID != "1001" {
+				t.Errorf("user id = %q, want 1001", event.User.ID)
+			}
+
+			body, _ := json.Marshal(event)
+			for _, secret := range append(secrets, strings.TrimPrefix(auth, "tma ")) {
+				if strings.Contains(string(body), secret) {
+					t.Errorf("event contains %q: %s", secret, body)
+				}
+			}
+		})
+	}
+}
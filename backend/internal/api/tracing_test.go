@@ -0,0 +1,115 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/db"
+	"wishlist-go/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+// глобальный провайдер подхватывается трассировщиком сервисов один раз, поэтому и экспортер один на все прогоны
+var spanExporter = sync.OnceValue(func() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg := &config.AppConfigStruct{}
+	cfg.Tracing.SampleRatio = 1
+	if _, err := tracing.Setup(cfg, exporter); err != nil {
+		panic(err)
+	}
+	return exporter
+})
+
+func TestTracingNestsServiceAndQuerySpans(t *testing.T) {
+	exporter := spanExporter()
+	exporter.Reset()
+
+	// база как в TestMain, но с плагином трассировки, как в db.ConnectDB
+	conn, err := sql.Open("fakedb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orm, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := orm.Use(gormtracing.NewPlugin(gormtracing.WithoutQueryVariables(), gormtracing.WithoutMetrics())); err != nil {
+		t.Fatal(err)
+	}
+	previous := db.ORM
+	db.ORM = orm
+	t.Cleanup(func() { db.ORM = previous })
+
+	router := gin.New()
+	router.Use(middleware.Tracing("wishlist-test"))
+	router.Use(middleware.Errors())
+	Register(router)
+
+	req := httptest.NewRequest("GET", "/api/v1/list/"+ownList.String()+"/wishes", nil)
+	req.Header.Set("Authorization", "tma "+initData(testUserID))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", w.Code, w.Body)
+	}
+
+	spans := exporter.GetSpans()
+	byID := make(map[trace.SpanID]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byID[span.SpanContext.SpanID()] = span
+	}
+	parent := func(span tracetest.SpanStub) tracetest.SpanStub {
+		return byID[span.Parent.SpanID()]
+	}
+	find := func(name string) tracetest.SpanStub {
+		for _, span := range spans {
+			if span.Name == name {
+				return span
+			}
+		}
+		t.Fatalf("no span %q", name)
+		return tracetest.SpanStub{}
+	}
+
+	root := find("GET /api/v1/list/:listId/wishes")
+	if root.SpanKind != trace.SpanKindServer || root.Parent.IsValid() {
+		t.Fatalf("request span is not a root server span: kind %v, parent %v", root.SpanKind, root.Parent.SpanID())
+	}
+	resolve := find("ShareTokenService.Resolve")
+	if parent(resolve).Name != root.Name {
+		t.Errorf("parent of %s = %q, want %q", resolve.Name, parent(resolve).Name, root.Name)
+	}
+	role := find("MembershipService.Role")
+	if parent(role).Name != resolve.Name {
+		t.Errorf("parent of %s = %q, want %q", role.Name, parent(role).Name, resolve.Name)
+	}
+
+	var queries int
+	for _, span := range spans {
+		// запросы к базе - клиентские спаны плагина gorm
+		if span.SpanKind != trace.SpanKindClient {
+			continue
+		}
+		queries++
+		if owner := parent(span); owner.Name == "" || owner.Name == root.Name {
+			t.Errorf("query span is not nested in a service span, parent %q", owner.Name)
+		}
+		if span.SpanContext.TraceID() != root.SpanContext.TraceID() {
+			t.Errorf("query span belongs to another trace")
+		}
+	}
+	if queries == 0 {
+		t.Error("no gorm query spans")
+	}
+}
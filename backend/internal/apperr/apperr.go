@@ -0,0 +1,100 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind - класс ошибки, по нему выбирается HTTP-статус
+type Kind string
+
+const (
+	KindValidation       Kind = "validation"
+	KindUnauthorized     Kind = "unauthorized"
+	KindForbidden        Kind = "forbidden"
+	KindNotFound         Kind = "not_found"
+	KindMethodNotAllowed Kind = "method_not_allowed"
+	KindConflict         Kind = "conflict"
+	KindGone             Kind = "gone"
+	KindRateLimited      Kind = "rate_limited"
+	KindInternal         Kind = "internal"
+)
+
+var statuses = map[Kind]int{
+	KindValidation:       http.StatusBadRequest,
+	KindUnauthorized:     http.StatusUnauthorized,
+	KindForbidden:        http.StatusForbidden,
+	KindNotFound:         http.StatusNotFound,
+	KindMethodNotAllowed: http.StatusMethodNotAllowed,
+	KindConflict:         http.StatusConflict,
+	KindGone:             http.StatusGone,
+	KindRateLimited:      http.StatusTooManyRequests,
+	KindInternal:         http.StatusInternalServerError,
+}
+
+// Error - доменная ошибка с машиночитаемым кодом. Message - текст по умолчанию (английский),
+// переводы по коду лежат в messages.go. Err - исходная причина, клиенту не показывается.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details any
+	Err     error
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is сравнивает по коду, чтобы копии из WithDetails и Wrap совпадали с исходной ошибкой
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// WithDetails возвращает копию ошибки с подробностями для клиента (например, ошибки по полям)
+func (e *Error) WithDetails(details any) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap возвращает копию ошибки с исходной причиной для логов и Sentry
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+var (
+	ErrInternal       = New(KindInternal, "internal_error", "internal server error")
+	ErrUnauthorized   = New(KindUnauthorized, "unauthorized", "unauthorized")
+	ErrInvalidRequest = New(KindValidation, "invalid_request", "invalid request")
+)
+
+// From приводит любую ошибку к доменной; все неизвестные считаются внутренними
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
@@ -0,0 +1,85 @@
+package apperr
+
+import "strings"
+
+// переводы сообщений по коду ошибки; английский текст берется из самой ошибки
+var messages = map[string]map[string]string{
+	"ru": {
+		"internal_error":    "Внутренняя ошибка сервера, попробуйте позже",
+		"unauthorized":      "Требуется авторизация через Telegram",
+		"invalid_request":   "Некорректный запрос",
+		"validation_failed": "Некоторые поля заполнены неверно",
+
+		"route_not_found":    "Такого адреса нет",
+		"method_not_allowed": "Метод не поддерживается для этого адреса",
+
+		"wrong_auth_header":          "Неверный заголовок авторизации",
+		"invalid_token_format":       "Неверный формат данных авторизации",
+		"invalid_user_data":          "Некорректные данные пользователя",
+		"invalid_telegram_auth_data": "Данные Telegram не прошли проверку",
+		"invalid_telegram_user_id":   "Не удалось определить пользователя Telegram",
+
+		"invalid_limit":         "Некорректный limit",
+		"invalid_cursor":        "Некорректный курсор страницы",
+		"invalid_sort":          "Недопустимое поле сортировки",
+		"invalid_move":          "Желание нельзя поставить рядом с самим собой",
+		"same_list":             "Желания уже в этом списке",
+		"invalid_wish_item_id":  "Некорректный идентификатор желания",
+		"invalid_account_id":    "Некорректный идентификатор пользователя",
+		"invalid_invite_code":   "Некорректный код приглашения",
+		"invalid_share_link_id": "Некорректный идентификатор ссылки",
+		"invalid_telegram_id":   "Некорректный идентификатор Telegram",
+		"invalid_user_id":       "Некорректный идентификатор пользователя",
+		"invalid_tag_id":        "Некорректный идентификатор метки",
+
+		"wishlist_not_found":     "Список не найден",
+		"wish_item_not_found":    "Желание не найдено",
+		"reservation_not_found":  "Резерв не найден",
+		"contribution_not_found": "Взнос не найден",
+		"member_not_found":       "Участник не найден",
+		"invite_not_found":       "Приглашение не найдено",
+		"share_link_not_found":   "Ссылка не найдена",
+		"allowed_user_not_found": "Пользователь не найден в списке доступа",
+		"account_not_found":      "Аккаунт не найден",
+		"calendar_not_found":     "Календарь не найден",
+		"tag_not_found":          "Метка не найдена",
+
+		"editor_cannot_reserve":    "Редакторы списка не могут резервировать желания",
+		"editor_cannot_contribute": "Редакторы списка не могут скидываться на желания",
+
+		"forbidden":               "Недостаточно прав",
+		"invalid_role":            "Неизвестная роль",
+		"invite_expired":          "Срок действия приглашения истек",
+		"primary_owner":           "Создателя списка нельзя удалить или понизить",
+		"invalid_visibility":      "Неизвестный режим видимости",
+		"share_link_revoked":      "Ссылка уже отозвана",
+		"passphrase_required":     "Список защищен кодовой фразой",
+		"wrong_passphrase":        "Неверная кодовая фраза",
+		"passphrase_too_long":     "Кодовая фраза длиннее 72 байт",
+		"too_many_attempts":       "Слишком много попыток, попробуйте позже",
+		"invalid_amount":          "Сумма должна быть положительной",
+		"currency_mismatch":       "Валюта взноса должна совпадать с валютой желания",
+		"own_wish_fund":           "Нельзя скидываться на собственное желание",
+		"wish_not_fundable":       "Желание уже зарезервировано, собрано или подарено",
+		"not_enough_quantity":     "Осталось недостаточно для резерва",
+		"own_wish_reserve":        "Нельзя зарезервировать собственное желание",
+		"invalid_quantity":        "Количество должно быть положительным",
+		"wish_not_reservable":     "Желание уже подарено или собрано вскладчину",
+		"quantity_below_reserved": "Количество не может быть меньше уже зарезервированного",
+		"invalid_occasion":        "Некорректный повод",
+		"tag_exists":              "Метка с таким названием уже есть",
+		"move_to_other_owner":     "Переносить желания можно только между списками одного владельца",
+
+		"currency_has_contributions": "Нельзя сменить валюту желания, на которое уже скинулись",
+	},
+}
+
+// Localize возвращает сообщение ошибки на языке пользователя (language_code из Telegram: "ru", "en-US"...),
+// а если перевода нет - английский текст по умолчанию
+func Localize(err *Error, languageCode string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if message, ok := messages[lang][err.Code]; ok {
+		return message
+	}
+	return err.Message
+}
@@ -1,40 +1,82 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Любое поле можно переопределить переменной окружения из тега env,
+// секреты - еще и файлом, путь к которому лежит в переменной <env>_FILE (docker/k8s secrets).
 type AppConfigStruct struct {
 	Server struct {
-		Host string `yaml:"host"`
-		Port int    `yaml:"port"`
+		Host string `yaml:"host" env:"SERVER_HOST"`
+		Port int    `yaml:"port" env:"SERVER_PORT"`
+		// таймауты соединения и время на завершение активных запросов при остановке
+		ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+		WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+		IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+		// если заданы оба, сервер слушает HTTPS
+		TLSCertFile string `yaml:"tls_cert_file" env:"SERVER_TLS_CERT_FILE"`
+		TLSKeyFile  string `yaml:"tls_key_file" env:"SERVER_TLS_KEY_FILE"`
+		// адрес, по которому backend доступен снаружи (за прокси - адрес прокси); из него строятся ссылки на ленты календаря
+		PublicURL string `yaml:"public_url" env:"SERVER_PUBLIC_URL"`
 	} `yaml:"server"`
 	Worker struct {
-		Host string `yaml:"host"`
-		Port int    `yaml:"port"`
+		Host string `yaml:"host" env:"WORKER_HOST"`
+		Port int    `yaml:"port" env:"WORKER_PORT"`
+		// как часто запускать фоновые задачи и за сколько до истечения резерва напоминать о нем
+		Interval       time.Duration `yaml:"interval" env:"WORKER_INTERVAL"`
+		ReminderBefore time.Duration `yaml:"reminder_before" env:"WORKER_REMINDER_BEFORE"`
+		// за сколько дней до повода напоминать тем, кто открывал список
+		OccasionReminderDays []int `yaml:"occasion_reminder_days" env:"WORKER_OCCASION_REMINDER_DAYS"`
 	}
 	Database struct {
-		Host     string `yaml:"host"`
-		Port     int    `yaml:"port"`
-		User     string `yaml:"user"`
-		Password string `yaml:"password"`
-		Name     string `yaml:"name"`
+		Host     string `yaml:"host" env:"DATABASE_HOST"`
+		Port     int    `yaml:"port" env:"DATABASE_PORT"`
+		User     string `yaml:"user" env:"DATABASE_USER"`
+		Password string `yaml:"password" env:"DATABASE_PASSWORD"`
+		Name     string `yaml:"name" env:"DATABASE_NAME"`
 	}
 	Telegram struct {
-		BotToken string `yaml:"bot_token"`
+		BotToken string `yaml:"bot_token" env:"TELEGRAM_BOT_TOKEN"`
+		// ссылка на мини-приложение (https://t.me/<bot>/<app>) для приглашений в списки
+		WebAppURL string `yaml:"web_app_url" env:"TELEGRAM_WEB_APP_URL"`
 	} `yaml:"telegram"`
 	Sentry struct {
-		DSN         string `yaml:"dsn"`
-		Environment string `yaml:"environment"`
-		Release     string `yaml:"release"`
+		DSN         string `yaml:"dsn" env:"SENTRY_DSN"`
+		Environment string `yaml:"environment" env:"SENTRY_ENVIRONMENT"`
+		Release     string `yaml:"release" env:"SENTRY_RELEASE"`
+	}
+	Tracing struct {
+		// адрес OTLP/HTTP коллектора (host:4318); пусто - трассировка отключена
+		Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+		ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+		SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
 	}
 	Logging struct {
-		Level string `yaml:"level"`
-		File  string `yaml:"file"`
+		Level string `yaml:"level" env:"LOGGING_LEVEL"`
+		File  string `yaml:"file" env:"LOGGING_FILE"`
+		// text или json
+		Format string `yaml:"format" env:"LOGGING_FORMAT"`
+		// дублировать ли записи в stdout, когда задан файл
+		EnableConsole bool `yaml:"enable_console" env:"LOGGING_ENABLE_CONSOLE"`
+		// ротация файла лога: размер одного файла, сколько старых хранить и сколько дней
+		MaxSizeMB  int `yaml:"max_size_mb" env:"LOGGING_MAX_SIZE_MB"`
+		MaxBackups int `yaml:"max_backups" env:"LOGGING_MAX_BACKUPS"`
+		MaxAgeDays int `yaml:"max_age_days" env:"LOGGING_MAX_AGE_DAYS"`
 	}
 }
 
@@ -44,20 +86,200 @@ var (
 	Config  *AppConfigStruct
 )
 
+var logLevels = []string{"DEBUG", "INFO", "WARN", "WARNING", "ERROR"}
+
+func defaultConfig() *AppConfigStruct {
+	cfg := &AppConfigStruct{}
+	cfg.Server.Host = "0.0.0.0"
+	cfg.Server.Port = 8080
+	cfg.Server.ReadTimeout = 15 * time.Second
+	cfg.Server.WriteTimeout = 30 * time.Second
+	cfg.Server.IdleTimeout = 2 * time.Minute
+	cfg.Server.ShutdownTimeout = 20 * time.Second
+	cfg.Worker.Host = "0.0.0.0"
+	cfg.Worker.Port = 8090
+	cfg.Worker.Interval = time.Minute
+	cfg.Worker.ReminderBefore = 24 * time.Hour
+	cfg.Worker.OccasionReminderDays = []int{7, 3, 1}
+	cfg.Database.Host = "localhost"
+	cfg.Database.Port = 5432
+	cfg.Tracing.ServiceName = "wishlist-backend"
+	cfg.Tracing.SampleRatio = 1
+	cfg.Logging.Level = "INFO"
+	cfg.Logging.Format = "text"
+	cfg.Logging.EnableConsole = true
+	cfg.Logging.MaxSizeMB = 100
+	cfg.Logging.MaxBackups = 5
+	cfg.Logging.MaxAgeDays = 30
+	return cfg
+}
+
+// LoadConfigFile собирает конфигурацию: значения по умолчанию, затем файл, затем окружение.
+// Файла может не быть, если все задано через окружение. Ошибки проверки возвращаются все сразу.
 func LoadConfigFile(configPath string) error {
 	once.Do(func() {
+		cfg := defaultConfig()
 
 		data, err := os.ReadFile(configPath)
-		if err != nil {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
 			loadErr = fmt.Errorf("failed to read config file: %w", err)
 			return
+		default:
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				loadErr = fmt.Errorf("failed to unmarshal config: %w", err)
+				return
+			}
 		}
 
-		if err := yaml.Unmarshal(data, &Config); err != nil {
-			loadErr = fmt.Errorf("failed to unmarshal config: %w", err)
+		// ошибки окружения и проверки собираются вместе, чтобы исправить все за один раз
+		if err := errors.Join(applyEnv(reflect.ValueOf(cfg).Elem()), cfg.Validate()); err != nil {
+			loadErr = fmt.Errorf("invalid config:\n%w", err)
 			return
 		}
+		Config = cfg
 	})
 
 	return loadErr
 }
+
+// applyEnv обходит структуру и подставляет значения из переменных окружения по тегу env
+func applyEnv(v reflect.Value) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			errs = append(errs, applyEnv(field))
+			continue
+		}
+		name := v.Type().Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw, ok, err := lookupEnv(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lookupEnv читает переменную name или файл из name_FILE; задавать обе сразу нельзя.
+// Пустая переменная считается незаданной: docker-compose подставляет "" вместо отсутствующих.
+func lookupEnv(name string) (string, bool, error) {
+	value := os.Getenv(name)
+	ok := value != ""
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("%s and %s_FILE are both set", name, name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case []int:
+		// список через запятую: "7,3,1"
+		var values []int
+		for part := range strings.SplitSeq(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return err
+			}
+			values = append(values, n)
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные ошибки
+func (c *AppConfigStruct) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	validPort := func(port int) bool { return port > 0 && port <= 65535 }
+	validPublicURL := func(raw string) bool {
+		u, err := url.Parse(raw)
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.RawQuery == "" && u.Fragment == ""
+	}
+
+	check(validPort(c.Server.Port), "server.port: %d is not a valid port", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout: must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout: must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout: must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout: must be positive")
+	check((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""),
+		"server.tls_cert_file and server.tls_key_file must be set together")
+	check(validPublicURL(c.Server.PublicURL), "server.public_url: must be an http(s) URL like https://wish.example.com")
+	check(validPort(c.Worker.Port), "worker.port: %d is not a valid port", c.Worker.Port)
+	check(c.Worker.Interval > 0, "worker.interval: must be positive")
+	check(c.Worker.ReminderBefore > 0, "worker.reminder_before: must be positive")
+	for _, days := range c.Worker.OccasionReminderDays {
+		check(days > 0, "worker.occasion_reminder_days: %d is not a positive number of days", days)
+	}
+	check(c.Database.Host != "", "database.host: must not be empty")
+	check(validPort(c.Database.Port), "database.port: %d is not a valid port", c.Database.Port)
+	check(c.Database.User != "", "database.user: must not be empty")
+	check(c.Database.Name != "", "database.name: must not be empty")
+	check(strings.TrimSpace(c.Telegram.BotToken) != "", "telegram.bot_token: must not be empty")
+	check(c.Telegram.WebAppURL == "" || strings.HasPrefix(c.Telegram.WebAppURL, "https://"),
+		"telegram.web_app_url: must be an https URL")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be between 0 and 1")
+	level := strings.ToUpper(c.Logging.Level)
+	check(level == "" || slices.Contains(logLevels, level), "logging.level: unknown level %q", c.Logging.Level)
+	format := strings.ToLower(c.Logging.Format)
+	check(format == "" || format == "text" || format == "json", "logging.format: must be text or json")
+	check(c.Logging.MaxSizeMB >= 0 && c.Logging.MaxBackups >= 0 && c.Logging.MaxAgeDays >= 0,
+		"logging: rotation limits must not be negative")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+	"wishlist-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migration - ручная миграция для того, что AutoMigrate не умеет (индексы по выражениям и т.п.)
+type migration struct {
+	name string
+	sql  string
+}
+
+// migrations применяются по порядку и один раз; выполненные записываются в таблицу migrations.
+// Новые добавляются только в конец, уже примененные не меняются.
+var migrations = []migration{
+	{
+		name: "0001_wish_items_search_index",
+		// выражение должно совпадать с service.searchVector
+		sql: `CREATE INDEX IF NOT EXISTS idx_wish_items_search ON wish_items
+			USING GIN (to_tsvector('russian', name || ' ' || notes))`,
+	},
+	{
+		name: "0002_wish_items_position",
+		// существующие желания выстраиваются в порядке создания
+		sql: `UPDATE wish_items SET position = ranked.n * 1024
+			FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY wish_list_code ORDER BY created_at, id) AS n FROM wish_items) ranked
+			WHERE wish_items.id = ranked.id`,
+	},
+	{
+		name: "0003_public_profile_tokens",
+		// ссылки профиля теперь выпускаются при публикации списка; уже публичным выдаем их здесь.
+		// 16 байт из gen_random_uuid в base64url - как у service.newShareToken
+		sql: `INSERT INTO share_tokens (wish_list_id, token, label, created_by_id, created_at)
+			SELECT id, translate(rtrim(encode(decode(replace(gen_random_uuid()::text, '-', ''), 'hex'), 'base64'), '='), '+/', '-_'),
+				'public profile', owner_id, extract(epoch FROM now())::bigint
+			FROM wish_lists
+			WHERE visibility = 'public' AND NOT EXISTS (
+				SELECT 1 FROM share_tokens WHERE share_tokens.wish_list_id = wish_lists.id
+					AND label = 'public profile' AND revoked_at IS NULL AND expires_at IS NULL)`,
+	},
+	{
+		name: "0004_default_share_tokens",
+		// списки, созданные до ссылок, получают ссылку "default"; значение - прежний share_code,
+		// чтобы уже разосланные ссылки продолжали открываться
+		sql: `INSERT INTO share_tokens (wish_list_id, token, label, created_by_id, created_at)
+			SELECT id, share_code::text, 'default', owner_id, extract(epoch FROM now())::bigint
+			FROM wish_lists
+			WHERE NOT EXISTS (SELECT 1 FROM share_tokens WHERE share_tokens.wish_list_id = wish_lists.id AND label = 'default')`,
+	},
+}
+
+func runMigrations(db *gorm.DB) error {
+	var applied []string
+	if err := db.Model(&models.Migration{}).Pluck("name", &applied).Error; err != nil {
+		return err
+	}
+	done := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		done[name] = true
+	}
+	for _, m := range migrations {
+		if done[m.name] {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.sql).Error; err != nil {
+				return err
+			}
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Migration{Name: m.name}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
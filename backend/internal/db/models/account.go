@@ -1,7 +1,12 @@
 package models
 
+import "github.com/google/uuid"
+
 type Account struct {
 	ID        int64 `gorm:"primaryKey" json:"id"` // telegram-id
 	CreatedAt int64 `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt int64 `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// секрет ссылки на iCal-ленту поводов, создается по запросу
+	CalendarToken *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"-"`
 }
@@ -0,0 +1,55 @@
+package models
+
+import "github.com/google/uuid"
+
+// роли участников списка, по возрастанию прав
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// видимость списка для тех, кто не состоит в нем
+const (
+	VisibilityPrivate = "private" // только участники
+	VisibilityLink    = "link"    // любой, у кого есть ссылка
+	VisibilityInvite  = "invite"  // только пользователи из белого списка
+	VisibilityPublic  = "public"  // по ссылке и в публичном профиле владельца
+)
+
+// WishListMember - участник совместного списка; создатель списка (WishList.OwnerID) всегда владелец и здесь не хранится
+type WishListMember struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishListID int64  `gorm:"uniqueIndex:idx_member_list_account;not null" json:"wishlist_id"`
+	AccountID  int64  `gorm:"uniqueIndex:idx_member_list_account;index;not null" json:"account_id"`
+	Role       string `gorm:"not null" json:"role"`
+	CreatedAt  int64  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  int64  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	WishList WishList `json:"-" gorm:"foreignKey:WishListID"`
+	Account  Account  `json:"-" gorm:"foreignKey:AccountID"`
+}
+
+// WishListInvite - ссылка-приглашение в список с заданной ролью
+type WishListInvite struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"code"`
+	WishListID  int64     `gorm:"index;not null" json:"wishlist_id"`
+	Role        string    `gorm:"not null" json:"role"`
+	CreatedByID int64     `gorm:"not null" json:"created_by"`
+	ExpiresAt   *int64    `json:"expires_at,omitempty"`
+	CreatedAt   int64     `gorm:"autoCreateTime" json:"created_at"`
+
+	WishList  WishList `json:"-" gorm:"foreignKey:WishListID"`
+	CreatedBy Account  `json:"-" gorm:"foreignKey:CreatedByID"`
+}
+
+// WishListAllowedUser - пользователь Telegram, которому открыт список с видимостью invite
+type WishListAllowedUser struct {
+	ID         int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishListID int64 `gorm:"uniqueIndex:idx_allowed_list_user;not null" json:"wishlist_id"`
+	TelegramID int64 `gorm:"uniqueIndex:idx_allowed_list_user;not null" json:"telegram_id"`
+	CreatedAt  int64 `gorm:"autoCreateTime" json:"created_at"`
+
+	WishList WishList `json:"-" gorm:"foreignKey:WishListID"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// ShareToken - отзываемая ссылка для гостей; постоянный ShareCode списка гостям больше не выдается
+type ShareToken struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishListID  int64  `gorm:"index;not null" json:"wishlist_id"`
+	Token       string `gorm:"uniqueIndex;not null" json:"token"`
+	Label       string `gorm:"not null;default:''" json:"label"`
+	CreatedByID int64  `gorm:"not null" json:"created_by"`
+	ExpiresAt   *int64 `json:"expires_at,omitempty"`
+	RevokedAt   *int64 `json:"revoked_at,omitempty"`
+	CreatedAt   int64  `gorm:"autoCreateTime" json:"created_at"`
+
+	WishList  WishList `json:"-" gorm:"foreignKey:WishListID"`
+	CreatedBy Account  `json:"-" gorm:"foreignKey:CreatedByID"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// Tag - метка пользователя для группировки желаний: "книги", "техника", "кухня"
+type Tag struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	AccountID int64  `gorm:"not null;uniqueIndex:idx_tags_account_name" json:"account_id"`
+	Name      string `gorm:"not null;uniqueIndex:idx_tags_account_name" json:"name"`
+	CreatedAt int64  `gorm:"autoCreateTime" json:"created_at"`
+
+	Account Account `json:"-" gorm:"foreignKey:AccountID"`
+}
+
+// WishItemTag - метка на желании; метки берутся из аккаунта владельца желания
+type WishItemTag struct {
+	WishItemID int64 `gorm:"primaryKey" json:"wish_item_id"`
+	TagID      int64 `gorm:"primaryKey;index" json:"tag_id"`
+
+	WishItem WishItem `json:"-" gorm:"foreignKey:WishItemID"`
+	Tag      Tag      `json:"-" gorm:"foreignKey:TagID"`
+}
@@ -1,45 +1,117 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type WishList struct {
 	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
 	OwnerID     int64     `gorm:"index;not null" json:"owner"`
 	Name        string    `gorm:"not null" json:"name"`
 	Description string    `gorm:"not null" json:"description"`
-	ShareCode   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"share_code"`
+	ShareCode   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"share_code,omitzero"` // только для участников
 	CreatedAt   int64     `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   int64     `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// кто видит список помимо участников: private, link, invite, public
+	Visibility string `gorm:"not null;default:'link'" json:"visibility"`
+	// необязательная кодовая фраза для гостей, хранится только bcrypt-хеш
+	PassphraseHash string `gorm:"not null;default:''" json:"-"`
+	Protected      bool   `gorm:"not null;default:false" json:"protected"`
+
+	// срок резерва по умолчанию в днях, 0 - резервы не истекают
+	ReservationTTLDays int `gorm:"not null;default:0" json:"reservation_ttl_days"`
+
+	// повод списка: тип (birthday, wedding, new_year, other), дата и повторение (none, yearly)
+	OccasionType       string     `gorm:"not null;default:''" json:"occasion_type"`
+	OccasionDate       *time.Time `gorm:"type:date" json:"occasion_date,omitempty"`
+	OccasionRecurrence string     `gorm:"not null;default:'none'" json:"occasion_recurrence"`
+	// сколько дней осталось до ближайшего повода, вычисляется при выдаче
+	DaysLeft *int `gorm:"-" json:"days_left,omitempty"`
+	// ссылка, по которой гость открыл список, вместо постоянного ShareCode
+	ShareToken string `gorm:"-" json:"share_token,omitempty"`
+
 	Owner Account `json:"-" gorm:"foreignKey:OwnerID"`
 }
 
 type WishItem struct {
 	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	WishListCode   uuid.UUID `gorm:"type:uuid; index;not null" json:"wishlist_code"`
+	WishListCode   uuid.UUID `gorm:"type:uuid; index;not null" json:"wishlist_code,omitzero"`
 	OwnerID        int64     `gorm:"index;not null" json:"owner_id"`
 	Name           string    `gorm:"not null" json:"name"`
 	Priority       int       `gorm:"not null" json:"priority"`
-	Status         string    `gorm:"not null" json:"status"` // возможные значения: "pending", "reserved", "purchased"
+	Status         string    `gorm:"not null" json:"status"` // возможные значения: "pending", "reserved", "funded", "purchased"
 	MarketLink     string    `gorm:"not null" json:"market_link"`
 	MarketPicture  string    `gorm:"not null" json:"market_picture"`
 	MarketPrice    float64   `gorm:"not null" json:"market_price"`
 	MarketCurrency string    `gorm:"not null" json:"market_currency"`
 	MarketQuantity int       `gorm:"not null" json:"market_quantity"`
-	CreatedAt      int64     `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt      int64     `gorm:"autoUpdateTime" json:"updated_at"`
+	// заметки владельца: размер, цвет, где купить; участвуют в поиске вместе с названием
+	Notes string `gorm:"not null;default:''" json:"notes"`
+	// место в списке, заданное владельцем; дробное, чтобы перемещение меняло одну строку
+	Position  float64 `gorm:"not null;default:0;index" json:"position"`
+	CreatedAt int64   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt int64   `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// заполняется только для гостей: сколько единиц уже зарезервировано
+	ReservedQuantity *int `gorm:"-" json:"reserved_quantity,omitempty"`
+	// заполняется только для гостей: сколько уже собрано на совместный подарок
+	FundedAmount *float64 `gorm:"-" json:"funded_amount,omitempty"`
+	// метки владельца; хранятся в wish_item_tags и подставляются сервисом
+	Tags []Tag `gorm:"-" json:"tags"`
 
 	Owner    Account  `json:"-" gorm:"foreignKey:OwnerID"`
 	WishList WishList `json:"-" gorm:"foreignKey:WishListCode;references:ShareCode"`
 }
 
 type WishReservation struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishID     int64  `gorm:"uniqueIndex:idx_reservation_wish_reserver;not null" json:"wish_id"`
+	ReserverID int64  `gorm:"uniqueIndex:idx_reservation_wish_reserver;index;not null" json:"reserver"`
+	Quantity   int    `gorm:"not null;default:1" json:"quantity"`
+	ExpiresAt  *int64 `gorm:"index" json:"expires_at,omitempty"`
+	RemindedAt *int64 `json:"-"`
+	CreatedAt  int64  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  int64  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Wish     WishItem `json:"-" gorm:"foreignKey:WishID"`
+	Reserver Account  `json:"-" gorm:"foreignKey:ReserverID"`
+}
+
+// WishContribution - вклад участника в совместный подарок, в валюте желания
+type WishContribution struct {
+	ID            int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishID        int64   `gorm:"uniqueIndex:idx_contribution_wish_contributor;not null" json:"wish_id"`
+	ContributorID int64   `gorm:"uniqueIndex:idx_contribution_wish_contributor;index;not null" json:"contributor"`
+	Amount        float64 `gorm:"not null" json:"amount"`
+	Currency      string  `gorm:"not null" json:"currency"`
+	CreatedAt     int64   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     int64   `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Wish        WishItem `json:"-" gorm:"foreignKey:WishID"`
+	Contributor Account  `json:"-" gorm:"foreignKey:ContributorID"`
+}
+
+// WishListView - факт того, что пользователь открывал чужой список; нужен для напоминаний о поводе
+type WishListView struct {
 	ID         int64 `gorm:"primaryKey;autoIncrement" json:"id"`
-	WishID     int64 `gorm:"index;not null" json:"wish_id"`
-	ReserverID int64 `gorm:"index;not null" json:"reserver"`
+	WishListID int64 `gorm:"uniqueIndex:idx_view_list_account;not null" json:"wishlist_id"`
+	AccountID  int64 `gorm:"uniqueIndex:idx_view_list_account;index;not null" json:"account_id"`
 	CreatedAt  int64 `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt  int64 `gorm:"autoUpdateTime" json:"updated_at"`
 
-	Wish     WishItem `json:"-" gorm:"foreignKey:WishID"`
-	Reserver Account  `json:"-" gorm:"foreignKey:ReserverID"`
+	WishList WishList `json:"-" gorm:"foreignKey:WishListID"`
+	Account  Account  `json:"-" gorm:"foreignKey:AccountID"`
+}
+
+// OccasionReminder - отправленное напоминание "осталось N дней", чтобы не слать его повторно
+type OccasionReminder struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WishListID   int64     `gorm:"uniqueIndex:idx_occasion_reminder;not null" json:"wishlist_id"`
+	AccountID    int64     `gorm:"uniqueIndex:idx_occasion_reminder;not null" json:"account_id"`
+	OccasionDate time.Time `gorm:"type:date;uniqueIndex:idx_occasion_reminder;not null" json:"occasion_date"`
+	DaysLeft     int       `gorm:"uniqueIndex:idx_occasion_reminder;not null" json:"days_left"`
+	CreatedAt    int64     `gorm:"autoCreateTime" json:"created_at"`
 }
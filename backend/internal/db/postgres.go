@@ -1,20 +1,27 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 	"wishlist-go/internal/config"
 	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/metrics"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 var ORM *gorm.DB
 
-func dsnFromConfig() string {
-	log.Println(config.Config)
+// время успешного AutoMigrate при старте, для проверки готовности
+var migratedAt time.Time
 
+func dsnFromConfig() string {
 	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		config.Config.Database.Host, config.Config.Database.Port, config.Config.Database.User,
@@ -24,11 +31,19 @@ func dsnFromConfig() string {
 
 func ConnectDB() (err error) {
 	if ORM != nil {
-		log.Println("ORM is already initialized")
+		slog.Warn("ORM is already initialized")
 		return nil
 	}
 	masterDSN := dsnFromConfig()
-	db, err := gorm.Open(postgres.Open(masterDSN), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(masterDSN), &gorm.Config{
+		// запросы пишутся без значений параметров, чтобы в лог не попадали пользовательские данные
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			LogLevel:                  logger.Warn,
+			SlowThreshold:             200 * time.Millisecond,
+			IgnoreRecordNotFoundError: true,
+			ParameterizedQueries:      true,
+		}),
+	})
 	if err != nil {
 		return err
 	}
@@ -38,12 +53,89 @@ func ConnectDB() (err error) {
 		&models.WishList{},
 		&models.WishItem{},
 		&models.WishReservation{},
+		&models.WishContribution{},
+		&models.WishListView{},
+		&models.OccasionReminder{},
+		&models.WishListMember{},
+		&models.WishListInvite{},
+		&models.WishListAllowedUser{},
+		&models.ShareToken{},
+		&models.Tag{},
+		&models.WishItemTag{},
 		&models.Migration{},
 	)
 	if err != nil {
 		return fmt.Errorf("auto migration failed: %w", err)
 	}
+	if err := runMigrations(db); err != nil {
+		return err
+	}
+	migratedAt = time.Now()
+
+	// спаны на каждый запрос; значения параметров в трассы не попадают
+	if err := db.Use(tracing.NewPlugin(tracing.WithoutQueryVariables(), tracing.WithoutMetrics())); err != nil {
+		return fmt.Errorf("tracing plugin failed: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := metrics.RegisterDBStats(sqlDB); err != nil {
+		slog.Warn("failed to register db stats", "error", err)
+	}
 
 	ORM = db
 	return nil
 }
+
+// Close закрывает пул соединений; вызывается при остановке сервера
+func Close() error {
+	if ORM == nil {
+		return nil
+	}
+	sqlDB, err := ORM.DB()
+	if err != nil {
+		return err
+	}
+	ORM = nil
+	return sqlDB.Close()
+}
+
+// Ping проверяет, что база доступна
+func Ping(ctx context.Context) error {
+	if ORM == nil {
+		return errors.New("database is not connected")
+	}
+	sqlDB, err := ORM.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+type MigrationStatus struct {
+	AutoMigratedAt time.Time `json:"auto_migrated_at"`
+	Applied        int64     `json:"applied"`
+	Last           string    `json:"last,omitempty"`
+}
+
+// Migrations сообщает, прошел ли AutoMigrate и какие ручные миграции записаны в таблицу migrations
+func Migrations(ctx context.Context) (*MigrationStatus, error) {
+	if migratedAt.IsZero() {
+		return nil, errors.New("auto migration has not run")
+	}
+	status := &MigrationStatus{AutoMigratedAt: migratedAt}
+	query := ORM.WithContext(ctx).Model(&models.Migration{})
+	if err := query.Count(&status.Applied).Error; err != nil {
+		return nil, err
+	}
+	if status.Applied > 0 {
+		var last models.Migration
+		if err := ORM.WithContext(ctx).Order("id DESC").First(&last).Error; err != nil {
+			return nil, err
+		}
+		status.Last = last.Name
+	}
+	return status, nil
+}
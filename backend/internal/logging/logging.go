@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"wishlist-go/internal/config"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const redacted = "[REDACTED]"
+
+// атрибуты с такими подстроками в имени никогда не попадают в лог
+var secretKeys = []string{"password", "passphrase", "token", "secret", "authorization", "dsn", "grant", "hash"}
+
+type ctxKey struct{}
+
+// Setup настраивает slog по секции logging и делает его логгером по умолчанию,
+// в том числе для стандартного пакета log. Возвращает файл лога, который нужно закрыть при остановке.
+func Setup(cfg *config.AppConfigStruct) (io.Closer, error) {
+	level, err := parseLevel(cfg.Logging.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = os.Stdout
+	var closer io.Closer = io.NopCloser(nil)
+	if cfg.Logging.File != "" {
+		file := &lumberjack.Logger{
+			Filename:   cfg.Logging.File,
+			MaxSize:    cfg.Logging.MaxSizeMB,
+			MaxBackups: cfg.Logging.MaxBackups,
+			MaxAge:     cfg.Logging.MaxAgeDays,
+		}
+		out = file
+		if cfg.Logging.EnableConsole {
+			out = io.MultiWriter(os.Stdout, file)
+		}
+		closer = file
+	}
+
+	// значения секретов вычищаются и из текста сообщений и ошибок, если вдруг туда попали
+	var secrets []string
+	for _, secret := range []string{cfg.Telegram.BotToken, cfg.Database.Password, cfg.Sentry.DSN} {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactor(strings.NewReplacer(replacerPairs(secrets)...)),
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Logging.Format) {
+	case "json":
+		handler = slog.NewJSONHandler(out, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(out, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Logging.Format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return closer, nil
+}
+
+func parseLevel(value string) (slog.Level, error) {
+	switch strings.ToUpper(value) {
+	case "DEBUG":
+		return slog.LevelDebug, nil
+	case "INFO", "":
+		return slog.LevelInfo, nil
+	case "WARN", "WARNING":
+		return slog.LevelWarn, nil
+	case "ERROR":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", value)
+}
+
+func replacerPairs(secrets []string) []string {
+	pairs := make([]string, 0, len(secrets)*2)
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, redacted)
+	}
+	return pairs
+}
+
+func redactor(scrub *strings.Replacer) func(groups []string, attr slog.Attr) slog.Attr {
+	return func(groups []string, attr slog.Attr) slog.Attr {
+		key := strings.ToLower(attr.Key)
+		for _, secret := range secretKeys {
+			if strings.Contains(key, secret) {
+				return slog.String(attr.Key, redacted)
+			}
+		}
+		switch attr.Value.Kind() {
+		case slog.KindString:
+			return slog.String(attr.Key, scrub.Replace(attr.Value.String()))
+		case slog.KindAny:
+			if err, ok := attr.Value.Any().(error); ok {
+				return slog.String(attr.Key, scrub.Replace(err.Error()))
+			}
+		}
+		return attr
+	}
+}
+
+// WithLogger кладет логгер запроса в контекст
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext возвращает логгер запроса (с request_id и user_id) или логгер по умолчанию
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "wishlist"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// причины отказа в TelegramAuthMiddleware
+	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Rejected Telegram auth attempts by reason.",
+	}, []string{"reason"})
+
+	WishlistsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "wishlists_created_total",
+		Help:      "Wishlists created.",
+	})
+
+	WishItemsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "wish_items_created_total",
+		Help:      "Wish items created.",
+	})
+
+	// action: reserved, cancelled, expired
+	Reservations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_total",
+		Help:      "Reservation changes by action.",
+	}, []string{"action"})
+
+	// action: pledged, withdrawn
+	Contributions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "contributions_total",
+		Help:      "Group gift contribution changes by action.",
+	}, []string{"action"})
+)
+
+// RegisterDBStats публикует статистику пула соединений (открытые, занятые, ожидания)
+func RegisterDBStats(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, namespace))
+}
@@ -0,0 +1,70 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"time"
+	"wishlist-go/internal/config"
+
+	"github.com/getsentry/sentry-go"
+)
+
+const flushTimeout = 2 * time.Second
+
+// InitSentry включает отправку ошибок по секции sentry; без DSN отчеты отключены.
+// transport == nil - отправка в Sentry по HTTP, для проверок можно передать MemoryTransport.
+func InitSentry(cfg *config.AppConfigStruct, transport sentry.Transport) error {
+	if cfg.Sentry.DSN == "" && transport == nil {
+		return nil
+	}
+	return sentry.Init(sentry.ClientOptions{
+		Dsn:         cfg.Sentry.DSN,
+		Environment: cfg.Sentry.Environment,
+		Release:     cfg.Sentry.Release,
+		Transport:   transport,
+		// заголовки с initData и IP пользователей не отправляем
+		SendDefaultPII: false,
+	})
+}
+
+// Flush дожидается отправки накопленных событий; вызывается при остановке
+func Flush() {
+	sentry.Flush(flushTimeout)
+}
+
+// MemoryTransport складывает события в память вместо отправки в Sentry
+type MemoryTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Configure(sentry.ClientOptions) {}
+
+func (t *MemoryTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *MemoryTransport) Flush(time.Duration) bool { return true }
+
+func (t *MemoryTransport) FlushWithContext(context.Context) bool { return true }
+
+func (t *MemoryTransport) Close() {}
+
+// Events возвращает копию полученных событий
+func (t *MemoryTransport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = nil
+}
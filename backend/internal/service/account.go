@@ -1,24 +1,45 @@
 package service
 
 import (
-	"wishlist-go/internal/db"
+	"context"
+	"wishlist-go/internal/apperr"
 	"wishlist-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type AccountService struct{}
+var ErrAccountNotFound = apperr.New(apperr.KindNotFound, "account_not_found", "account not found")
+
+type AccountService struct {
+	base
+}
 
 func NewAccountService() *AccountService {
 	return &AccountService{}
 }
 
+// WithContext привязывает сервис к контексту запроса
+func (s *AccountService) WithContext(ctx context.Context) *AccountService {
+	s.ctx = ctx
+	return s
+}
+
 func (s *AccountService) Get(telegramId int64) (*models.Account, error) {
+	ctx, span := s.start("AccountService.Get")
+	defer span.End()
 	var account *models.Account
-	err := db.ORM.Model(&models.Account{}).Where("id = ?", telegramId).First(&account).Error
-	return account, err
+	err := orm(ctx).Model(&models.Account{}).Where("id = ?", telegramId).First(&account).Error
+	if err != nil {
+		return nil, notFound(err, ErrAccountNotFound)
+	}
+	return account, nil
 }
 
 func (s *AccountService) Create(telegramId int64) (*models.Account, error) {
-	err := db.ORM.Model(&models.Account{}).Create(&models.Account{ID: telegramId}).Error
+	ctx, span := s.start("AccountService.Create")
+	defer span.End()
+	err := orm(ctx).Model(&models.Account{}).Create(&models.Account{ID: telegramId}).Error
 	if err != nil {
 		return nil, err
 	}
@@ -26,5 +47,13 @@ func (s *AccountService) Create(telegramId int64) (*models.Account, error) {
 }
 
 func (s *AccountService) Delete(telegramId int64) error {
-	return db.ORM.Model(&models.Account{}).Delete(&models.Account{ID: telegramId}).Error
+	ctx, span := s.start("AccountService.Delete")
+	defer span.End()
+	return orm(ctx).Model(&models.Account{}).Delete(&models.Account{ID: telegramId}).Error
+}
+
+// ensureAccount создает аккаунт, если его еще нет. Middleware авторизации создает его асинхронно,
+// а первый же запрос нового пользователя может записать строку со ссылкой на аккаунт.
+func ensureAccount(tx *gorm.DB, telegramId int64) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Account{ID: telegramId}).Error
 }
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrCalendarNotFound - по секрету из ссылки не нашлось ленты (ссылку перевыпустили)
+var ErrCalendarNotFound = apperr.New(apperr.KindNotFound, "calendar_not_found", "calendar not found")
+
+type CalendarService struct {
+	base
+}
+
+func NewCalendarService() *CalendarService {
+	return &CalendarService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *CalendarService) WithContext(ctx context.Context) *CalendarService {
+	s.ctx = ctx
+	return s
+}
+
+// Token возвращает секрет календарной ленты пользователя, создавая его при первом обращении
+func (s *CalendarService) Token(accountID int64) (uuid.UUID, error) {
+	ctx, span := s.start("CalendarService.Token")
+	defer span.End()
+	var account models.Account
+	if err := orm(ctx).Model(&models.Account{}).Where("id = ?", accountID).First(&account).Error; err != nil {
+		return uuid.Nil, notFound(err, ErrAccountNotFound)
+	}
+	if account.CalendarToken != nil {
+		return *account.CalendarToken, nil
+	}
+	return NewCalendarService().WithContext(ctx).RotateToken(accountID)
+}
+
+// RotateToken выдает новый секрет, старая ссылка перестает работать
+func (s *CalendarService) RotateToken(accountID int64) (uuid.UUID, error) {
+	ctx, span := s.start("CalendarService.RotateToken")
+	defer span.End()
+	token := uuid.New()
+	err := orm(ctx).Model(&models.Account{}).Where("id = ?", accountID).Update("calendar_token", token).Error
+	return token, err
+}
+
+// Feed собирает iCalendar-ленту с поводами своих списков, списков, где пользователь участник, и открытых по ссылке
+func (s *CalendarService) Feed(token uuid.UUID) (string, error) {
+	ctx, span := s.start("CalendarService.Feed")
+	defer span.End()
+	var account models.Account
+	if err := orm(ctx).Model(&models.Account{}).Where("calendar_token = ?", token).First(&account).Error; err != nil {
+		return "", notFound(err, ErrCalendarNotFound)
+	}
+
+	var wishlists []models.WishList
+	err := orm(ctx).Model(&models.WishList{}).
+		Where("occasion_date IS NOT NULL").
+		Where("owner_id = ? OR id IN (?) OR id IN (?)", account.ID,
+			orm(ctx).Model(&models.WishListMember{}).Select("wish_list_id").Where("account_id = ?", account.ID),
+			orm(ctx).Model(&models.WishListView{}).Select("wish_list_id").Where("account_id = ?", account.ID)).
+		Order("id").
+		Find(&wishlists).Error
+	if err != nil {
+		return "", err
+	}
+
+	// список могли сделать приватным уже после того, как пользователь его открыл
+	visible := wishlists[:0]
+	for _, wishlist := range wishlists {
+		ok, err := CanView(ctx, &wishlist, account.ID)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			visible = append(visible, wishlist)
+		}
+	}
+
+	return renderCalendar(visible), nil
+}
+
+func renderCalendar(wishlists []models.WishList) string {
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldLine(line))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//wishlist-go//Wishlist occasions//RU")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:Wishlist")
+	for _, wishlist := range wishlists {
+		start := *wishlist.OccasionDate
+		writeLine("BEGIN:VEVENT")
+		// UID завязан на id списка, чтобы календарь обновлял событие, а не дублировал его
+		writeLine(fmt.Sprintf("UID:wishlist-%d@wishlist-go", wishlist.ID))
+		writeLine("DTSTAMP:" + time.Unix(wishlist.UpdatedAt, 0).UTC().Format("20060102T150405Z"))
+		writeLine("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+		writeLine("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format("20060102"))
+		if wishlist.OccasionRecurrence == "yearly" {
+			writeLine("RRULE:FREQ=YEARLY")
+		}
+		writeLine("SUMMARY:" + escapeText(wishlist.Name))
+		if wishlist.Description != "" {
+			writeLine("DESCRIPTION:" + escapeText(wishlist.Description))
+		}
+		if wishlist.OccasionType != "" {
+			writeLine("CATEGORIES:" + escapeText(wishlist.OccasionType))
+		}
+		writeLine("TRANSP:TRANSPARENT")
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+// escapeText экранирует значение TEXT по RFC 5545 (3.3.11)
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// foldLine переносит строки длиннее 75 октетов (RFC 5545, 3.1), не разрывая UTF-8 символы
+func foldLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"wishlist-go/internal/db"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var tracer = otel.Tracer("wishlist-go/internal/service")
+
+// base - общая часть сервисов: контекст запроса, к которому привязываются спаны и запросы к базе.
+// Контекст задается через WithContext каждого сервиса; без него используется context.Background.
+type base struct {
+	ctx context.Context
+}
+
+// start открывает спан метода; возвращенный контекст передается в orm и вложенные вызовы
+func (b *base) start(name string) (context.Context, trace.Span) {
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return tracer.Start(ctx, name)
+}
+
+// orm - db.ORM, привязанный к контексту: запросы попадают в трассу и отменяются вместе с запросом
+func orm(ctx context.Context) *gorm.DB {
+	return db.ORM.WithContext(ctx)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/metrics"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAmount    = apperr.New(apperr.KindValidation, "invalid_amount", "amount must be positive")
+	ErrCurrencyMismatch = apperr.New(apperr.KindValidation, "currency_mismatch", "contribution currency must match wish currency")
+	ErrOwnWishFund      = apperr.New(apperr.KindForbidden, "own_wish_fund", "owner can't contribute to own wish")
+	ErrWishNotFundable  = apperr.New(apperr.KindConflict, "wish_not_fundable", "wish is already reserved, funded or purchased")
+
+	ErrCurrencyHasContributions = apperr.New(apperr.KindConflict, "currency_has_contributions", "can't change currency of a wish with contributions")
+
+	ErrContributionNotFound = apperr.New(apperr.KindNotFound, "contribution_not_found", "contribution not found")
+)
+
+type ContributionService struct {
+	base
+}
+
+func NewContributionService() *ContributionService {
+	return &ContributionService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *ContributionService) WithContext(ctx context.Context) *ContributionService {
+	s.ctx = ctx
+	return s
+}
+
+func fundedAmount(tx *gorm.DB, wishID int64) (float64, error) {
+	var funded float64
+	err := tx.Model(&models.WishContribution{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("wish_id = ?", wishID).
+		Scan(&funded).Error
+	return funded, err
+}
+
+// syncFundedStatus переводит желание в "funded", когда взносы покрывают цену, и обратно, если взнос отозвали
+func syncFundedStatus(tx *gorm.DB, wish *models.WishItem) error {
+	if wish.MarketPrice <= 0 {
+		return nil
+	}
+	funded, err := fundedAmount(tx, wish.ID)
+	if err != nil {
+		return err
+	}
+	status := wish.Status
+	switch {
+	case funded >= wish.MarketPrice && (wish.Status == "pending" || wish.Status == "reserved"):
+		status = "funded"
+	case funded < wish.MarketPrice && wish.Status == "funded":
+		status = "pending"
+	}
+	if status == wish.Status {
+		return nil
+	}
+	wish.Status = status
+	if err := tx.Model(&models.WishItem{}).Where("id = ?", wish.ID).Update("status", status).Error; err != nil {
+		return err
+	}
+	// после отзыва взноса желание могло остаться полностью зарезервированным
+	return syncWishStatus(tx, wish)
+}
+
+// Pledge сохраняет взнос пользователя; повторный вызов заменяет сумму его взноса
+func (s *ContributionService) Pledge(shareCode uuid.UUID, wishID int64, contributorID int64, amount float64, currency string) (*models.WishContribution, error) {
+	ctx, span := s.start("ContributionService.Pledge")
+	defer span.End()
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	var contribution models.WishContribution
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		wish, err := lockWish(tx, wishID, shareCode)
+		if err != nil {
+			return err
+		}
+		if wish.OwnerID == contributorID {
+			return ErrOwnWishFund
+		}
+		if currency != "" && !strings.EqualFold(currency, wish.MarketCurrency) {
+			return ErrCurrencyMismatch
+		}
+
+		err = tx.Where("wish_id = ? AND contributor_id = ?", wish.ID, contributorID).First(&contribution).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// новые взносы принимаются только на свободное желание; свой взнос в уже собранное можно изменить
+		pledged := err == nil
+		if wish.Status != "pending" && !(pledged && wish.Status == "funded") {
+			return ErrWishNotFundable
+		}
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			contribution = models.WishContribution{
+				WishID:        wish.ID,
+				ContributorID: contributorID,
+				Amount:        amount,
+				Currency:      wish.MarketCurrency,
+			}
+			err = tx.Create(&contribution).Error
+		case err == nil:
+			contribution.Amount = amount
+			contribution.Currency = wish.MarketCurrency
+			err = tx.Model(&contribution).Updates(map[string]interface{}{
+				"amount":   amount,
+				"currency": wish.MarketCurrency,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return syncFundedStatus(tx, wish)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.Contributions.WithLabelValues("pledged").Inc()
+	return &contribution, nil
+}
+
+// Withdraw отзывает взнос пользователя
+func (s *ContributionService) Withdraw(shareCode uuid.UUID, wishID int64, contributorID int64) error {
+	ctx, span := s.start("ContributionService.Withdraw")
+	defer span.End()
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		wish, err := lockWish(tx, wishID, shareCode)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("wish_id = ? AND contributor_id = ?", wish.ID, contributorID).Delete(&models.WishContribution{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrContributionNotFound
+		}
+
+		return syncFundedStatus(tx, wish)
+	})
+	if err == nil {
+		metrics.Contributions.WithLabelValues("withdrawn").Inc()
+	}
+	return err
+}
+
+// FillFunded проставляет желаниям собранную сумму для гостевого просмотра
+func (s *ContributionService) FillFunded(wishItems []models.WishItem) error {
+	ctx, span := s.start("ContributionService.FillFunded")
+	defer span.End()
+	if len(wishItems) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(wishItems))
+	for _, item := range wishItems {
+		ids = append(ids, item.ID)
+	}
+
+	var rows []struct {
+		WishID int64
+		Funded float64
+	}
+	err := orm(ctx).Model(&models.WishContribution{}).
+		Select("wish_id, SUM(amount) AS funded").
+		Where("wish_id IN ?", ids).
+		Group("wish_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	funded := make(map[int64]float64, len(rows))
+	for _, row := range rows {
+		funded[row.WishID] = row.Funded
+	}
+	for i := range wishItems {
+		amount := funded[wishItems[i].ID]
+		wishItems[i].FundedAmount = &amount
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+	"wishlist-go/internal/apperr"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// код ошибки PostgreSQL при нарушении уникального индекса
+const uniqueViolation = "23505"
+
+// notFound подменяет gorm.ErrRecordNotFound доменной ошибкой, чтобы обработчики
+// отличали отсутствующую запись от сбоя базы; остальные ошибки возвращаются как есть
+func notFound(err error, target *apperr.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return target
+	}
+	return err
+}
+
+// duplicate подменяет нарушение уникального индекса доменной ошибкой: проверку занятости
+// делает сама база, без отдельного запроса и гонки между ним и записью
+func duplicate(err error, target *apperr.Error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return target
+	}
+	return err
+}
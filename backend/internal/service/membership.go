@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultInviteTTL = 7 * 24 * time.Hour
+
+var (
+	ErrForbidden     = apperr.New(apperr.KindForbidden, "forbidden", "forbidden")
+	ErrInvalidRole   = apperr.New(apperr.KindValidation, "invalid_role", "invalid role")
+	ErrInviteExpired = apperr.New(apperr.KindGone, "invite_expired", "invite expired")
+	ErrPrimaryOwner  = apperr.New(apperr.KindConflict, "primary_owner", "list creator can't be removed or demoted")
+
+	ErrMemberNotFound = apperr.New(apperr.KindNotFound, "member_not_found", "member not found")
+	ErrInviteNotFound = apperr.New(apperr.KindNotFound, "invite_not_found", "invite not found")
+)
+
+var roleRanks = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleOwner:  3,
+}
+
+// RoleAtLeast проверяет, что роль дает права не ниже required; пустая роль - посторонний пользователь
+func RoleAtLeast(role string, required string) bool {
+	return roleRanks[role] >= roleRanks[required] && roleRanks[role] > 0
+}
+
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+type MembershipService struct {
+	base
+}
+
+func NewMembershipService() *MembershipService {
+	return &MembershipService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *MembershipService) WithContext(ctx context.Context) *MembershipService {
+	s.ctx = ctx
+	return s
+}
+
+func roleInList(ctx context.Context, wishlist *models.WishList, accountID int64) (string, error) {
+	if wishlist.OwnerID == accountID {
+		return models.RoleOwner, nil
+	}
+	var member models.WishListMember
+	err := orm(ctx).Model(&models.WishListMember{}).
+		Where("wish_list_id = ? AND account_id = ?", wishlist.ID, accountID).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// Role возвращает список и роль пользователя в нем; пустая роль - пользователь не участник
+func (s *MembershipService) Role(accountID int64, shareCode uuid.UUID) (*models.WishList, string, error) {
+	ctx, span := s.start("MembershipService.Role")
+	defer span.End()
+	var wishlist models.WishList
+	if err := orm(ctx).Model(&models.WishList{}).Where("share_code = ?", shareCode).First(&wishlist).Error; err != nil {
+		return nil, "", notFound(err, ErrWishlistNotFound)
+	}
+	role, err := roleInList(ctx, &wishlist, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	return &wishlist, role, nil
+}
+
+// Authorize возвращает список, если роль пользователя в нем не ниже required, иначе ErrForbidden.
+// Постороннему, как и в Resolve, достается ErrWishlistNotFound, чтобы не раскрывать существование списка.
+func (s *MembershipService) Authorize(accountID int64, shareCode uuid.UUID, required string) (*models.WishList, string, error) {
+	ctx, span := s.start("MembershipService.Authorize")
+	defer span.End()
+	wishlist, role, err := NewMembershipService().WithContext(ctx).Role(accountID, shareCode)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", ErrWishlistNotFound
+	}
+	if !RoleAtLeast(role, required) {
+		return nil, role, ErrForbidden
+	}
+	return wishlist, role, nil
+}
+
+// Member - участник списка для выдачи, включая создателя
+type Member struct {
+	AccountID int64  `json:"account_id"`
+	Role      string `json:"role"`
+	Creator   bool   `json:"creator"`
+}
+
+func (s *MembershipService) Members(wishlist *models.WishList) ([]Member, error) {
+	ctx, span := s.start("MembershipService.Members")
+	defer span.End()
+	var rows []models.WishListMember
+	if err := orm(ctx).Model(&models.WishListMember{}).Where("wish_list_id = ?", wishlist.ID).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	members := []Member{{AccountID: wishlist.OwnerID, Role: models.RoleOwner, Creator: true}}
+	for _, row := range rows {
+		members = append(members, Member{AccountID: row.AccountID, Role: row.Role})
+	}
+	return members, nil
+}
+
+func (s *MembershipService) UpdateRole(wishlist *models.WishList, accountID int64, role string) error {
+	ctx, span := s.start("MembershipService.UpdateRole")
+	defer span.End()
+	if !ValidRole(role) {
+		return ErrInvalidRole
+	}
+	if wishlist.OwnerID == accountID {
+		return ErrPrimaryOwner
+	}
+	result := orm(ctx).Model(&models.WishListMember{}).
+		Where("wish_list_id = ? AND account_id = ?", wishlist.ID, accountID).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+func (s *MembershipService) RemoveMember(wishlist *models.WishList, accountID int64) error {
+	ctx, span := s.start("MembershipService.RemoveMember")
+	defer span.End()
+	if wishlist.OwnerID == accountID {
+		return ErrPrimaryOwner
+	}
+	result := orm(ctx).Where("wish_list_id = ? AND account_id = ?", wishlist.ID, accountID).Delete(&models.WishListMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// CreateInvite создает многоразовую ссылку-приглашение; ttl <= 0 - срок по умолчанию
+func (s *MembershipService) CreateInvite(wishlist *models.WishList, createdBy int64, role string, ttl time.Duration) (*models.WishListInvite, error) {
+	ctx, span := s.start("MembershipService.CreateInvite")
+	defer span.End()
+	if !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if ttl <= 0 {
+		ttl = defaultInviteTTL
+	}
+	expiresAt := time.Now().Add(ttl).Unix()
+	invite := &models.WishListInvite{
+		Code:        uuid.New(),
+		WishListID:  wishlist.ID,
+		Role:        role,
+		CreatedByID: createdBy,
+		ExpiresAt:   &expiresAt,
+	}
+	if err := orm(ctx).Create(invite).Error; err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+func (s *MembershipService) Invites(wishlist *models.WishList) ([]models.WishListInvite, error) {
+	ctx, span := s.start("MembershipService.Invites")
+	defer span.End()
+	var invites []models.WishListInvite
+	err := orm(ctx).Model(&models.WishListInvite{}).
+		Where("wish_list_id = ? AND (expires_at IS NULL OR expires_at > ?)", wishlist.ID, time.Now().Unix()).
+		Order("id").
+		Find(&invites).Error
+	return invites, err
+}
+
+func (s *MembershipService) RevokeInvite(wishlist *models.WishList, code uuid.UUID) error {
+	ctx, span := s.start("MembershipService.RevokeInvite")
+	defer span.End()
+	result := orm(ctx).Where("wish_list_id = ? AND code = ?", wishlist.ID, code).Delete(&models.WishListInvite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// AcceptInvite добавляет пользователя в список; роль уже состоящего участника не понижается
+func (s *MembershipService) AcceptInvite(code uuid.UUID, accountID int64) (*models.WishList, string, error) {
+	ctx, span := s.start("MembershipService.AcceptInvite")
+	defer span.End()
+	var invite models.WishListInvite
+	if err := orm(ctx).Model(&models.WishListInvite{}).Preload("WishList").Where("code = ?", code).First(&invite).Error; err != nil {
+		return nil, "", notFound(err, ErrInviteNotFound)
+	}
+	if invite.ExpiresAt != nil && *invite.ExpiresAt <= time.Now().Unix() {
+		return nil, "", ErrInviteExpired
+	}
+
+	current, err := roleInList(ctx, &invite.WishList, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	if RoleAtLeast(current, invite.Role) {
+		return &invite.WishList, current, nil
+	}
+
+	// приглашение часто первый запрос нового пользователя, аккаунта может еще не быть
+	err = orm(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureAccount(tx, accountID); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "wish_list_id"}, {Name: "account_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(&models.WishListMember{
+			WishListID: invite.WishListID,
+			AccountID:  accountID,
+			Role:       invite.Role,
+		}).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &invite.WishList, invite.Role, nil
+}
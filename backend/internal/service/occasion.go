@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	OccasionTypes       = []string{"birthday", "wedding", "new_year", "other"}
+	OccasionRecurrences = []string{"none", "yearly"}
+
+	ErrInvalidOccasion = apperr.New(apperr.KindValidation, "invalid_occasion", "invalid occasion")
+)
+
+type OccasionService struct {
+	base
+}
+
+func NewOccasionService() *OccasionService {
+	return &OccasionService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *OccasionService) WithContext(ctx context.Context) *OccasionService {
+	s.ctx = ctx
+	return s
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NextOccurrence возвращает ближайшую (сегодня или позже) дату повода; nil, если повода нет или он прошел
+func NextOccurrence(wishlist *models.WishList, now time.Time) *time.Time {
+	if wishlist.OccasionDate == nil {
+		return nil
+	}
+	today := truncateDay(now)
+	date := truncateDay(*wishlist.OccasionDate)
+
+	if wishlist.OccasionRecurrence == "yearly" {
+		date = time.Date(today.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if date.Before(today) {
+			date = date.AddDate(1, 0, 0)
+		}
+	}
+	if date.Before(today) {
+		return nil
+	}
+	return &date
+}
+
+// DaysLeft - количество полных дней до ближайшего повода
+func DaysLeft(wishlist *models.WishList, now time.Time) *int {
+	next := NextOccurrence(wishlist, now)
+	if next == nil {
+		return nil
+	}
+	days := int(next.Sub(truncateDay(now)).Hours() / 24)
+	return &days
+}
+
+// FillDaysLeft проставляет спискам обратный отсчет до повода
+func (s *OccasionService) FillDaysLeft(wishlists []models.WishList) {
+	_, span := s.start("OccasionService.FillDaysLeft")
+	defer span.End()
+	now := time.Now()
+	for i := range wishlists {
+		wishlists[i].DaysLeft = DaysLeft(&wishlists[i], now)
+	}
+}
+
+// RecordView запоминает, что пользователь открывал чужой список
+func (s *OccasionService) RecordView(wishlistID int64, accountID int64) error {
+	ctx, span := s.start("OccasionService.RecordView")
+	defer span.End()
+	return orm(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureAccount(tx, accountID); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "wish_list_id"}, {Name: "account_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"updated_at": time.Now().Unix()}),
+		}).Create(&models.WishListView{WishListID: wishlistID, AccountID: accountID}).Error
+	})
+}
+
+// OccasionReminderTarget - кому и о каком списке напомнить
+type OccasionReminderTarget struct {
+	WishList  models.WishList
+	AccountID int64
+	Date      time.Time
+	DaysLeft  int
+}
+
+// occasionDue отбирает списки, у которых повод наступает ровно через один из сроков reminderDays.
+// Годовщины сравниваются по месяцу и дню; 29 февраля в невисокосный год отмечается 1 марта, как в NextOccurrence.
+func occasionDue(query *gorm.DB, today time.Time, reminderDays []int) *gorm.DB {
+	dates := make([]string, 0, len(reminderDays))
+	monthDays := make([][]interface{}, 0, len(reminderDays))
+	for _, days := range reminderDays {
+		date := today.AddDate(0, 0, days)
+		dates = append(dates, date.Format(time.DateOnly))
+		monthDays = append(monthDays, []interface{}{int(date.Month()), date.Day()})
+		leap := time.Date(date.Year(), time.February, 29, 0, 0, 0, 0, time.UTC).Month() == time.February
+		if date.Month() == time.March && date.Day() == 1 && !leap {
+			monthDays = append(monthDays, []interface{}{2, 29})
+		}
+	}
+	return query.Where(
+		"occasion_date IN ? OR (occasion_recurrence = ? AND (EXTRACT(MONTH FROM occasion_date), EXTRACT(DAY FROM occasion_date)) IN ?)",
+		dates, "yearly", monthDays,
+	)
+}
+
+// DueReminders собирает получателей напоминаний "осталось N дней": тех, кто открывал список или скидывался
+// на желание из него, но ничего в нем не зарезервировал и еще не получал напоминание за этот срок
+func (s *OccasionService) DueReminders(reminderDays []int) ([]OccasionReminderTarget, error) {
+	ctx, span := s.start("OccasionService.DueReminders")
+	defer span.End()
+	if len(reminderDays) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	var wishlists []models.WishList
+	if err := occasionDue(orm(ctx).Model(&models.WishList{}), truncateDay(now), reminderDays).Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+
+	var targets []OccasionReminderTarget
+	for _, wishlist := range wishlists {
+		next := NextOccurrence(&wishlist, now)
+		if next == nil {
+			continue
+		}
+		daysLeft := *DaysLeft(&wishlist, now)
+		if !slices.Contains(reminderDays, daysLeft) {
+			continue
+		}
+
+		var accountIDs []int64
+		err := orm(ctx).Raw(`
+			SELECT a.account_id FROM (
+				SELECT account_id FROM wish_list_views WHERE wish_list_id = ?
+				UNION
+				SELECT c.contributor_id FROM wish_contributions c JOIN wish_items i ON i.id = c.wish_id
+				WHERE i.wish_list_code = ?
+			) a
+			WHERE NOT EXISTS (
+				SELECT 1 FROM wish_reservations r JOIN wish_items i ON i.id = r.wish_id
+				WHERE i.wish_list_code = ? AND r.reserver_id = a.account_id)
+			AND NOT EXISTS (
+				SELECT 1 FROM occasion_reminders o
+				WHERE o.wish_list_id = ? AND o.account_id = a.account_id
+				AND o.occasion_date = ? AND o.days_left = ?)`,
+			wishlist.ID, wishlist.ShareCode, wishlist.ShareCode, wishlist.ID, *next, daysLeft,
+		).Scan(&accountIDs).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, accountID := range accountIDs {
+			if ok, err := CanView(ctx, &wishlist, accountID); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+			targets = append(targets, OccasionReminderTarget{
+				WishList:  wishlist,
+				AccountID: accountID,
+				Date:      *next,
+				DaysLeft:  daysLeft,
+			})
+		}
+	}
+	return targets, nil
+}
+
+func (s *OccasionService) MarkReminded(target OccasionReminderTarget) error {
+	ctx, span := s.start("OccasionService.MarkReminded")
+	defer span.End()
+	return orm(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.OccasionReminder{
+		WishListID:   target.WishList.ID,
+		AccountID:    target.AccountID,
+		OccasionDate: target.Date,
+		DaysLeft:     target.DaysLeft,
+	}).Error
+}
+
+// ValidateOccasion проверяет тип и повторение повода
+func ValidateOccasion(occasionType *string, recurrence *string) error {
+	if occasionType != nil && *occasionType != "" && !slices.Contains(OccasionTypes, *occasionType) {
+		return ErrInvalidOccasion
+	}
+	if recurrence != nil && !slices.Contains(OccasionRecurrences, *recurrence) {
+		return ErrInvalidOccasion
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"wishlist-go/internal/apperr"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = apperr.New(apperr.KindValidation, "invalid_cursor", "invalid cursor")
+	ErrInvalidSort   = apperr.New(apperr.KindValidation, "invalid_sort", "invalid sort")
+)
+
+// PageRequest - параметры страницы: размер, курсор из прошлого ответа и порядок вида "price" или "-created"
+type PageRequest struct {
+	Limit  int
+	Cursor string
+	Sort   string
+}
+
+// Page - страница выдачи; NextCursor пустой на последней странице, Total - размер всей выдачи без учета страниц
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      int64
+}
+
+// sortKey - поле, по которому можно упорядочить выдачу; id записи добавляется к нему для однозначности
+type sortKey[T any] struct {
+	column string
+	value  func(*T) any
+	parse  func(json.RawMessage) (any, error)
+}
+
+func parseAs[V any](raw json.RawMessage) (any, error) {
+	var value V
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+// cursor - последняя запись страницы; следующая страница начинается строго после нее
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    int64           `json:"id"`
+}
+
+func encodeCursor(c cursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// paginate листает query по ключу (поле сортировки, id), а не по смещению, поэтому страницы
+// не съезжают, когда в выдачу добавляют или из нее удаляют записи.
+// table - таблица query, чтобы колонки не путались с колонками подзапросов и join.
+func paginate[T any](query *gorm.DB, table string, req PageRequest, keys map[string]sortKey[T], defaultSort string, id func(*T) int64) (*Page[T], error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	sort := req.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	name, desc := strings.CutPrefix(sort, "-")
+	key, ok := keys[name]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	page := &Page[T]{}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	column, idColumn := table+"."+key.column, table+".id"
+	direction, compare := "ASC", ">"
+	if desc {
+		direction, compare = "DESC", "<"
+	}
+	query = query.Order(fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction))
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor)
+		if err != nil || after.Sort != sort {
+			return nil, ErrInvalidCursor
+		}
+		value, err := key.parse(after.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", column, idColumn, compare), value, after.ID)
+	}
+
+	// одна лишняя запись показывает, есть ли следующая страница
+	if err := query.Limit(limit + 1).Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := &page.Items[limit-1]
+		value, err := json.Marshal(key.value(last))
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor, err = encodeCursor(cursor{Sort: sort, Value: value, ID: id(last)})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
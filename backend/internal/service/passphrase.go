@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/db/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	listGrantTTL = 2 * time.Hour
+
+	// не больше unlockMaxAttempts неудачных попыток за unlockWindow на пару пользователь+список
+	unlockMaxAttempts = 5
+	unlockWindow      = 15 * time.Minute
+)
+
+var (
+	ErrPassphraseRequired = apperr.New(apperr.KindForbidden, "passphrase_required", "passphrase required")
+	ErrWrongPassphrase    = apperr.New(apperr.KindForbidden, "wrong_passphrase", "wrong passphrase")
+	ErrTooManyAttempts    = apperr.New(apperr.KindRateLimited, "too_many_attempts", "too many unlock attempts")
+	ErrPassphraseTooLong  = apperr.New(apperr.KindValidation, "passphrase_too_long", "passphrase is longer than 72 bytes")
+)
+
+func HashPassphrase(passphrase string) (string, error) {
+	// bcrypt принимает не больше 72 байт; тег max=72 считает символы, а кириллица занимает по два байта
+	if len(passphrase) > 72 {
+		return "", ErrPassphraseTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(passphrase), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// grantKey выводит ключ подписи доступа из токена бота, чтобы не заводить отдельный секрет
+func grantKey() []byte {
+	h := hmac.New(sha256.New, []byte("ListGrant"))
+	h.Write([]byte(config.Config.Telegram.BotToken))
+	return h.Sum(nil)
+}
+
+func signGrant(payload string) string {
+	h := hmac.New(sha256.New, grantKey())
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IssueListGrant выдает подписанный доступ к защищенному списку, привязанный к пользователю
+func IssueListGrant(wishlistID int64, accountID int64) (string, time.Time) {
+	expiresAt := time.Now().Add(listGrantTTL)
+	payload := fmt.Sprintf("%d.%d.%d", wishlistID, accountID, expiresAt.Unix())
+	grant := base64.RawURLEncoding.EncodeToString([]byte(payload + "." + signGrant(payload)))
+	return grant, expiresAt
+}
+
+func VerifyListGrant(grant string, wishlistID int64, accountID int64) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(grant)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 4 {
+		return false
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(signGrant(payload))) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return false
+	}
+	return parts[0] == strconv.FormatInt(wishlistID, 10) && parts[1] == strconv.FormatInt(accountID, 10)
+}
+
+// unlockThrottle считает неудачные попытки в памяти процесса
+type unlockThrottle struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	sweptAt  time.Time
+}
+
+var throttle = &unlockThrottle{failures: make(map[string][]time.Time)}
+
+func throttleKey(wishlistID int64, accountID int64) string {
+	return fmt.Sprintf("%d:%d", wishlistID, accountID)
+}
+
+// prune оставляет попытки внутри окна; ключ без попыток удаляется. Вызывать под mu.
+func (t *unlockThrottle) prune(key string, now time.Time) []time.Time {
+	var recent []time.Time
+	for _, at := range t.failures[key] {
+		if now.Sub(at) < unlockWindow {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) == 0 {
+		delete(t.failures, key)
+		return nil
+	}
+	t.failures[key] = recent
+	return recent
+}
+
+// reserve засчитывает попытку до проверки фразы, чтобы параллельные запросы не проскочили лимит;
+// при превышении возвращает время, когда освободится следующая попытка
+func (t *unlockThrottle) reserve(key string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// раз в окно чистим ключи, по которым больше не пытались, чтобы карта не росла
+	if now.Sub(t.sweptAt) >= unlockWindow {
+		for k := range t.failures {
+			t.prune(k, now)
+		}
+		t.sweptAt = now
+	}
+	recent := t.prune(key, now)
+	if len(recent) >= unlockMaxAttempts {
+		return unlockWindow - now.Sub(recent[0])
+	}
+	t.failures[key] = append(recent, now)
+	return 0
+}
+
+// reset забывает попытки после верной фразы
+func (t *unlockThrottle) reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, key)
+}
+
+type PassphraseService struct {
+	base
+}
+
+func NewPassphraseService() *PassphraseService {
+	return &PassphraseService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *PassphraseService) WithContext(ctx context.Context) *PassphraseService {
+	s.ctx = ctx
+	return s
+}
+
+// Unlock проверяет кодовую фразу и выдает доступ; при превышении попыток возвращает время ожидания
+func (s *PassphraseService) Unlock(wishlist *models.WishList, accountID int64, passphrase string) (string, time.Time, time.Duration, error) {
+	_, span := s.start("PassphraseService.Unlock")
+	defer span.End()
+	if !wishlist.Protected {
+		grant, expiresAt := IssueListGrant(wishlist.ID, accountID)
+		return grant, expiresAt, 0, nil
+	}
+
+	key := throttleKey(wishlist.ID, accountID)
+	if retryAfter := throttle.reserve(key, time.Now()); retryAfter > 0 {
+		return "", time.Time{}, retryAfter, ErrTooManyAttempts
+	}
+	if bcrypt.CompareHashAndPassword([]byte(wishlist.PassphraseHash), []byte(passphrase)) != nil {
+		return "", time.Time{}, 0, ErrWrongPassphrase
+	}
+	throttle.reset(key)
+
+	grant, expiresAt := IssueListGrant(wishlist.ID, accountID)
+	return grant, expiresAt, 0, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+)
+
+var (
+	Visibilities = []string{models.VisibilityPrivate, models.VisibilityLink, models.VisibilityInvite, models.VisibilityPublic}
+
+	ErrInvalidVisibility   = apperr.New(apperr.KindValidation, "invalid_visibility", "invalid visibility")
+	ErrAllowedUserNotFound = apperr.New(apperr.KindNotFound, "allowed_user_not_found", "allowed user not found")
+)
+
+func ValidVisibility(visibility string) bool {
+	return slices.Contains(Visibilities, visibility)
+}
+
+// CheckListPolicy - единственное место, где решается, может ли пользователь открыть список.
+// Участники видят список всегда. Приватный список для остальных как будто не существует
+// (ErrWishlistNotFound), список по приглашениям открыт только белому списку (ErrForbidden),
+// защищенный кодовой фразой - только с действующим доступом grant (ErrPassphraseRequired).
+func CheckListPolicy(ctx context.Context, wishlist *models.WishList, role string, accountID int64, grant string) error {
+	if err := checkVisibility(ctx, wishlist, role, accountID); err != nil {
+		return err
+	}
+	if role == "" && wishlist.Protected && !VerifyListGrant(grant, wishlist.ID, accountID) {
+		return ErrPassphraseRequired
+	}
+	return nil
+}
+
+func checkVisibility(ctx context.Context, wishlist *models.WishList, role string, accountID int64) error {
+	if role != "" {
+		return nil
+	}
+	switch wishlist.Visibility {
+	case models.VisibilityLink, models.VisibilityPublic, "":
+		return nil
+	case models.VisibilityInvite:
+		var allowed bool
+		err := orm(ctx).Model(&models.WishListAllowedUser{}).
+			Select("count(*) > 0").
+			Where("wish_list_id = ? AND telegram_id = ?", wishlist.ID, accountID).
+			Find(&allowed).Error
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrForbidden
+		}
+		return nil
+	default:
+		return ErrWishlistNotFound
+	}
+}
+
+// CanView - та же политика для фоновых задач и лент, где роль пользователя заранее неизвестна.
+// Кодовая фраза защищает только открытие списка по ссылке, поэтому здесь не проверяется.
+func CanView(ctx context.Context, wishlist *models.WishList, accountID int64) (bool, error) {
+	role, err := roleInList(ctx, wishlist, accountID)
+	if err != nil {
+		return false, err
+	}
+	err = checkVisibility(ctx, wishlist, role, accountID)
+	if errors.Is(err, ErrWishlistNotFound) || errors.Is(err, ErrForbidden) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type PolicyService struct {
+	base
+}
+
+func NewPolicyService() *PolicyService {
+	return &PolicyService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *PolicyService) WithContext(ctx context.Context) *PolicyService {
+	s.ctx = ctx
+	return s
+}
+
+func (s *PolicyService) AllowedUsers(wishlist *models.WishList) ([]models.WishListAllowedUser, error) {
+	ctx, span := s.start("PolicyService.AllowedUsers")
+	defer span.End()
+	var users []models.WishListAllowedUser
+	err := orm(ctx).Model(&models.WishListAllowedUser{}).Where("wish_list_id = ?", wishlist.ID).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (s *PolicyService) AllowUser(wishlist *models.WishList, telegramID int64) (*models.WishListAllowedUser, error) {
+	ctx, span := s.start("PolicyService.AllowUser")
+	defer span.End()
+	user := models.WishListAllowedUser{WishListID: wishlist.ID, TelegramID: telegramID}
+	err := orm(ctx).Where(user).FirstOrCreate(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *PolicyService) DisallowUser(wishlist *models.WishList, telegramID int64) error {
+	ctx, span := s.start("PolicyService.DisallowUser")
+	defer span.End()
+	result := orm(ctx).Where("wish_list_id = ? AND telegram_id = ?", wishlist.ID, telegramID).Delete(&models.WishListAllowedUser{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAllowedUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"slices"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Порядок желаний в списке задается дробной позицией: перемещение ставит желание посередине
+// между соседями и меняет одну строку. Новые желания встают в конец с шагом positionStep.
+const (
+	positionStep = 1024
+	// positionMinGap - когда соседи сходятся ближе, список перенумеровывается заново с шагом positionStep
+	positionMinGap = 1e-6
+)
+
+var ErrInvalidMove = apperr.New(apperr.KindValidation, "invalid_move", "wish item can't be moved relative to itself")
+
+// nextPosition - позиция после последнего желания списка
+func nextPosition(tx *gorm.DB, shareCode uuid.UUID) (float64, error) {
+	var last float64
+	err := tx.Model(&models.WishItem{}).
+		Select("COALESCE(MAX(position), 0)").
+		Where("wish_list_code = ?", shareCode).
+		Scan(&last).Error
+	return last + positionStep, err
+}
+
+// Move ставит желание id сразу перед anchorID (before) или после него; остальные желания списка не меняются,
+// кроме редкой перенумерации, когда между соседями не осталось места
+func (s *WishItemService) Move(id, anchorID int64, before bool) (*models.WishItem, error) {
+	ctx, span := s.start("WishItemService.Move")
+	defer span.End()
+	if id == anchorID {
+		return nil, ErrInvalidMove
+	}
+
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		// блокируем весь список, чтобы параллельные перемещения не получили одну и ту же позицию
+		var items []models.WishItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "position").
+			Where("wish_list_code = ?", s.WishList).
+			Order("position, id").
+			Find(&items).Error
+		if err != nil {
+			return err
+		}
+		find := func(id int64) int {
+			return slices.IndexFunc(items, func(w models.WishItem) bool { return w.ID == id })
+		}
+		if find(id) < 0 || find(anchorID) < 0 {
+			return ErrWishItemNotFound
+		}
+		items = slices.Delete(items, find(id), find(id)+1)
+
+		position, ok := between(items, find(anchorID), before)
+		if !ok {
+			if err := rebalance(tx, items); err != nil {
+				return err
+			}
+			position, _ = between(items, find(anchorID), before)
+		}
+		return tx.Model(&models.WishItem{}).Where("id = ?", id).Update("position", position).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// between считает позицию рядом с items[anchor]; false - соседи слишком близко и нужна перенумерация
+func between(items []models.WishItem, anchor int, before bool) (float64, bool) {
+	if before {
+		if anchor == 0 {
+			return items[0].Position - positionStep, true
+		}
+		lo, hi := items[anchor-1].Position, items[anchor].Position
+		return (lo + hi) / 2, hi-lo > positionMinGap
+	}
+	if anchor == len(items)-1 {
+		return items[anchor].Position + positionStep, true
+	}
+	lo, hi := items[anchor].Position, items[anchor+1].Position
+	return (lo + hi) / 2, hi-lo > positionMinGap
+}
+
+// rebalance расставляет позиции заново с шагом positionStep, сохраняя порядок
+func rebalance(tx *gorm.DB, items []models.WishItem) error {
+	for i := range items {
+		items[i].Position = float64(i+1) * positionStep
+		if err := tx.Model(&models.WishItem{}).Where("id = ?", items[i].ID).Update("position", items[i].Position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/metrics"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotEnoughQuantity = apperr.New(apperr.KindConflict, "not_enough_quantity", "not enough quantity left to reserve")
+	ErrOwnWishReserve    = apperr.New(apperr.KindForbidden, "own_wish_reserve", "owner can't reserve own wish")
+	ErrInvalidQuantity   = apperr.New(apperr.KindValidation, "invalid_quantity", "quantity must be positive")
+	ErrWishNotReservable = apperr.New(apperr.KindConflict, "wish_not_reservable", "wish is already funded or purchased")
+	ErrBelowReserved     = apperr.New(apperr.KindConflict, "quantity_below_reserved", "quantity can't be lower than already reserved")
+
+	ErrReservationNotFound = apperr.New(apperr.KindNotFound, "reservation_not_found", "reservation not found")
+)
+
+type ReservationService struct {
+	base
+}
+
+func NewReservationService() *ReservationService {
+	return &ReservationService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *ReservationService) WithContext(ctx context.Context) *ReservationService {
+	s.ctx = ctx
+	return s
+}
+
+// wishQuantity - сколько единиц желания можно зарезервировать (не меньше одной)
+func wishQuantity(wish *models.WishItem) int {
+	if wish.MarketQuantity < 1 {
+		return 1
+	}
+	return wish.MarketQuantity
+}
+
+func reservedQuantity(tx *gorm.DB, wishID int64) (int, error) {
+	var reserved int
+	err := tx.Model(&models.WishReservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("wish_id = ?", wishID).
+		Scan(&reserved).Error
+	return reserved, err
+}
+
+// syncWishStatus переводит желание в "reserved", когда все единицы разобраны, и обратно в "pending"
+func syncWishStatus(tx *gorm.DB, wish *models.WishItem) error {
+	reserved, err := reservedQuantity(tx, wish.ID)
+	if err != nil {
+		return err
+	}
+	status := wish.Status
+	switch {
+	case reserved >= wishQuantity(wish) && wish.Status == "pending":
+		status = "reserved"
+	case reserved < wishQuantity(wish) && wish.Status == "reserved":
+		status = "pending"
+	}
+	if status == wish.Status {
+		return nil
+	}
+	wish.Status = status
+	return tx.Model(&models.WishItem{}).Where("id = ?", wish.ID).Update("status", status).Error
+}
+
+// lockWish блокирует строку желания до конца транзакции, чтобы параллельные резервы не превысили количество
+func lockWish(tx *gorm.DB, wishID int64, shareCode uuid.UUID) (*models.WishItem, error) {
+	var wish models.WishItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND wish_list_code = ?", wishID, shareCode).
+		First(&wish).Error
+	if err != nil {
+		return nil, notFound(err, ErrWishItemNotFound)
+	}
+	return &wish, nil
+}
+
+// reservationExpiry считает срок нового резерва по настройке списка; nil - резерв бессрочный
+func reservationExpiry(tx *gorm.DB, shareCode uuid.UUID) (*int64, error) {
+	var ttlDays int
+	err := tx.Model(&models.WishList{}).
+		Select("reservation_ttl_days").
+		Where("share_code = ?", shareCode).
+		Scan(&ttlDays).Error
+	if err != nil || ttlDays <= 0 {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(time.Duration(ttlDays) * 24 * time.Hour).Unix()
+	return &expiresAt, nil
+}
+
+// Reserve резервирует quantity единиц желания; повторный вызов тем же пользователем увеличивает его резерв
+func (s *ReservationService) Reserve(shareCode uuid.UUID, wishID int64, reserverID int64, quantity int) (*models.WishReservation, error) {
+	ctx, span := s.start("ReservationService.Reserve")
+	defer span.End()
+	if quantity < 1 {
+		return nil, ErrInvalidQuantity
+	}
+
+	var reservation models.WishReservation
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		wish, err := lockWish(tx, wishID, shareCode)
+		if err != nil {
+			return err
+		}
+		if wish.OwnerID == reserverID {
+			return ErrOwnWishReserve
+		}
+		// купленное или собранное вскладчину желание уже никто не дарит
+		if wish.Status != "pending" && wish.Status != "reserved" {
+			return ErrWishNotReservable
+		}
+
+		reserved, err := reservedQuantity(tx, wish.ID)
+		if err != nil {
+			return err
+		}
+		if reserved+quantity > wishQuantity(wish) {
+			return ErrNotEnoughQuantity
+		}
+
+		expiresAt, err := reservationExpiry(tx, shareCode)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("wish_id = ? AND reserver_id = ?", wish.ID, reserverID).First(&reservation).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			reservation = models.WishReservation{WishID: wish.ID, ReserverID: reserverID, Quantity: quantity, ExpiresAt: expiresAt}
+			err = tx.Create(&reservation).Error
+		case err == nil:
+			// увеличение резерва продлевает его срок
+			reservation.Quantity += quantity
+			reservation.ExpiresAt = expiresAt
+			reservation.RemindedAt = nil
+			err = tx.Model(&reservation).Updates(map[string]interface{}{
+				"quantity":    reservation.Quantity,
+				"expires_at":  expiresAt,
+				"reminded_at": nil,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return syncWishStatus(tx, wish)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.Reservations.WithLabelValues("reserved").Inc()
+	return &reservation, nil
+}
+
+// Renew продлевает резерв пользователя на срок из настроек списка, не меняя количество
+func (s *ReservationService) Renew(shareCode uuid.UUID, wishID int64, reserverID int64) (*models.WishReservation, error) {
+	ctx, span := s.start("ReservationService.Renew")
+	defer span.End()
+	var reservation models.WishReservation
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		wish, err := lockWish(tx, wishID, shareCode)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("wish_id = ? AND reserver_id = ?", wish.ID, reserverID).First(&reservation).Error
+		if err != nil {
+			return notFound(err, ErrReservationNotFound)
+		}
+		expiresAt, err := reservationExpiry(tx, shareCode)
+		if err != nil {
+			return err
+		}
+		reservation.ExpiresAt = expiresAt
+		reservation.RemindedAt = nil
+		return tx.Model(&reservation).Updates(map[string]interface{}{
+			"expires_at":  expiresAt,
+			"reminded_at": nil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.Reservations.WithLabelValues("renewed").Inc()
+	return &reservation, nil
+}
+
+// Cancel снимает резерв пользователя с желания
+func (s *ReservationService) Cancel(shareCode uuid.UUID, wishID int64, reserverID int64) error {
+	ctx, span := s.start("ReservationService.Cancel")
+	defer span.End()
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		wish, err := lockWish(tx, wishID, shareCode)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("wish_id = ? AND reserver_id = ?", wish.ID, reserverID).Delete(&models.WishReservation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReservationNotFound
+		}
+
+		return syncWishStatus(tx, wish)
+	})
+	if err == nil {
+		metrics.Reservations.WithLabelValues("cancelled").Inc()
+	}
+	return err
+}
+
+// FillReserved проставляет желаниям количество зарезервированных единиц для гостевого просмотра
+func (s *ReservationService) FillReserved(wishItems []models.WishItem) error {
+	ctx, span := s.start("ReservationService.FillReserved")
+	defer span.End()
+	if len(wishItems) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(wishItems))
+	for _, item := range wishItems {
+		ids = append(ids, item.ID)
+	}
+
+	var rows []struct {
+		WishID   int64
+		Reserved int
+	}
+	err := orm(ctx).Model(&models.WishReservation{}).
+		Select("wish_id, SUM(quantity) AS reserved").
+		Where("wish_id IN ?", ids).
+		Group("wish_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	reserved := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		reserved[row.WishID] = row.Reserved
+	}
+	for i := range wishItems {
+		quantity := reserved[wishItems[i].ID]
+		wishItems[i].ReservedQuantity = &quantity
+	}
+	return nil
+}
+
+// DueForReminder возвращает резервы, истекающие в ближайшие window, по которым еще не было напоминания.
+// Резерв короче двух window напоминает о себе на середине срока, а не сразу после создания.
+func (s *ReservationService) DueForReminder(window time.Duration) ([]models.WishReservation, error) {
+	ctx, span := s.start("ReservationService.DueForReminder")
+	defer span.End()
+	var reservations []models.WishReservation
+	now := time.Now().Unix()
+	err := orm(ctx).Model(&models.WishReservation{}).
+		Preload("Wish").
+		Where("expires_at IS NOT NULL AND expires_at > ?", now).
+		Where("expires_at - ? <= LEAST(?, (expires_at - updated_at) / 2)", now, int64(window/time.Second)).
+		Where("reminded_at IS NULL").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (s *ReservationService) MarkReminded(id int64) error {
+	ctx, span := s.start("ReservationService.MarkReminded")
+	defer span.End()
+	return orm(ctx).Model(&models.WishReservation{}).Where("id = ?", id).Update("reminded_at", time.Now().Unix()).Error
+}
+
+// ReleaseExpired снимает просроченные резервы и возвращает их, чтобы можно было уведомить резервистов
+func (s *ReservationService) ReleaseExpired() ([]models.WishReservation, error) {
+	ctx, span := s.start("ReservationService.ReleaseExpired")
+	defer span.End()
+	var expired []models.WishReservation
+	err := orm(ctx).Model(&models.WishReservation{}).
+		Preload("Wish").
+		Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now().Unix()).
+		Find(&expired).Error
+	if err != nil {
+		return nil, err
+	}
+
+	released := make([]models.WishReservation, 0, len(expired))
+	for _, reservation := range expired {
+		deleted := false
+		err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+			wish, err := lockWish(tx, reservation.WishID, reservation.Wish.WishListCode)
+			if err != nil {
+				return err
+			}
+			// резерв могли продлить или снять, пока мы ждали блокировку
+			result := tx.Where("id = ? AND expires_at <= ?", reservation.ID, time.Now().Unix()).Delete(&models.WishReservation{})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			deleted = true
+			return syncWishStatus(tx, wish)
+		})
+		if err != nil {
+			return released, err
+		}
+		if deleted {
+			metrics.Reservations.WithLabelValues("expired").Inc()
+			released = append(released, reservation)
+		}
+	}
+	return released, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// метка ссылки, которую публичный профиль выдает для списков с видимостью public
+const publicProfileLabel = "public profile"
+
+var (
+	ErrShareTokenRevoked = apperr.New(apperr.KindConflict, "share_link_revoked", "share link revoked")
+	ErrShareLinkNotFound = apperr.New(apperr.KindNotFound, "share_link_not_found", "share link not found")
+)
+
+type ShareTokenService struct {
+	base
+}
+
+func NewShareTokenService() *ShareTokenService {
+	return &ShareTokenService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *ShareTokenService) WithContext(ctx context.Context) *ShareTokenService {
+	s.ctx = ctx
+	return s
+}
+
+func newShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func createShareToken(tx *gorm.DB, wishlistID int64, createdBy int64, label string, ttl time.Duration) (*models.ShareToken, error) {
+	value, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	token := &models.ShareToken{
+		WishListID:  wishlistID,
+		Token:       value,
+		Label:       label,
+		CreatedByID: createdBy,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl).Unix()
+		token.ExpiresAt = &expiresAt
+	}
+	if err := tx.Create(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Create выпускает новую ссылку на список; ttl <= 0 - бессрочная
+func (s *ShareTokenService) Create(wishlist *models.WishList, createdBy int64, label string, ttl time.Duration) (*models.ShareToken, error) {
+	ctx, span := s.start("ShareTokenService.Create")
+	defer span.End()
+	return createShareToken(orm(ctx), wishlist.ID, createdBy, label, ttl)
+}
+
+func (s *ShareTokenService) GetAll(wishlist *models.WishList) ([]models.ShareToken, error) {
+	ctx, span := s.start("ShareTokenService.GetAll")
+	defer span.End()
+	var tokens []models.ShareToken
+	err := orm(ctx).Model(&models.ShareToken{}).Where("wish_list_id = ?", wishlist.ID).Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+func (s *ShareTokenService) Revoke(wishlist *models.WishList, tokenID int64) error {
+	ctx, span := s.start("ShareTokenService.Revoke")
+	defer span.End()
+	result := orm(ctx).Model(&models.ShareToken{}).
+		Where("id = ? AND wish_list_id = ? AND revoked_at IS NULL", tokenID, wishlist.ID).
+		Update("revoked_at", time.Now().Unix())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// Rotate отзывает ссылку и выпускает взамен новую с той же меткой и тем же сроком жизни
+func (s *ShareTokenService) Rotate(wishlist *models.WishList, tokenID int64, createdBy int64) (*models.ShareToken, error) {
+	ctx, span := s.start("ShareTokenService.Rotate")
+	defer span.End()
+	var rotated *models.ShareToken
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		var old models.ShareToken
+		if err := tx.Where("id = ? AND wish_list_id = ?", tokenID, wishlist.ID).First(&old).Error; err != nil {
+			return notFound(err, ErrShareLinkNotFound)
+		}
+		if old.RevokedAt != nil {
+			return ErrShareTokenRevoked
+		}
+		if err := tx.Model(&old).Update("revoked_at", time.Now().Unix()).Error; err != nil {
+			return err
+		}
+
+		var ttl time.Duration
+		if old.ExpiresAt != nil {
+			ttl = time.Duration(*old.ExpiresAt-old.CreatedAt) * time.Second
+		}
+		var err error
+		rotated, err = createShareToken(tx, wishlist.ID, createdBy, old.Label, ttl)
+		return err
+	})
+	return rotated, err
+}
+
+// publicTokens - действующие бессрочные ссылки публичного профиля
+func publicTokens(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&models.ShareToken{}).
+		Where("share_tokens.label = ? AND share_tokens.revoked_at IS NULL AND share_tokens.expires_at IS NULL", publicProfileLabel)
+}
+
+// ensurePublicToken выпускает ссылку для публичного профиля, если у списка ее еще нет
+func ensurePublicToken(tx *gorm.DB, wishlist *models.WishList) error {
+	var count int64
+	if err := publicTokens(tx).Where("share_tokens.wish_list_id = ?", wishlist.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := createShareToken(tx, wishlist.ID, wishlist.OwnerID, publicProfileLabel, 0)
+	return err
+}
+
+// PublicTokens возвращает ссылки публичного профиля по id списков; списки без ссылки в ответ не попадают
+func (s *ShareTokenService) PublicTokens(wishlists []models.WishList) (map[int64]string, error) {
+	ctx, span := s.start("ShareTokenService.PublicTokens")
+	defer span.End()
+	tokens := make(map[int64]string, len(wishlists))
+	if len(wishlists) == 0 {
+		return tokens, nil
+	}
+	ids := make([]int64, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		ids = append(ids, wishlist.ID)
+	}
+	var rows []models.ShareToken
+	if err := publicTokens(orm(ctx)).Where("share_tokens.wish_list_id IN ?", ids).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if _, ok := tokens[row.WishListID]; !ok {
+			tokens[row.WishListID] = row.Token
+		}
+	}
+	return tokens, nil
+}
+
+// Resolve находит список по идентификатору из маршрута и роль пользователя в нем.
+// Постоянный ShareCode работает только для участников, всем остальным нужна действующая ссылка;
+// старые ссылки по ShareCode открываются через ссылку "default" с тем же значением (миграция 0004).
+// В обоих случаях посторонний получает ErrWishlistNotFound, чтобы не раскрывать существование списка.
+func (s *ShareTokenService) Resolve(ref string, accountID int64) (*models.WishList, string, error) {
+	ctx, span := s.start("ShareTokenService.Resolve")
+	defer span.End()
+	if shareCode, err := uuid.Parse(ref); err == nil {
+		wishlist, role, err := NewMembershipService().WithContext(ctx).Role(accountID, shareCode)
+		if err != nil && !errors.Is(err, ErrWishlistNotFound) {
+			return nil, "", err
+		}
+		if role != "" {
+			return wishlist, role, nil
+		}
+	}
+
+	var token models.ShareToken
+	err := orm(ctx).Model(&models.ShareToken{}).
+		Preload("WishList").
+		Where("token = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", ref, time.Now().Unix()).
+		First(&token).Error
+	if err != nil {
+		return nil, "", notFound(err, ErrWishlistNotFound)
+	}
+	role, err := roleInList(ctx, &token.WishList, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	return &token.WishList, role, nil
+}
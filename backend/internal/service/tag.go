@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTagNotFound = apperr.New(apperr.KindNotFound, "tag_not_found", "tag not found")
+	ErrTagExists   = apperr.New(apperr.KindConflict, "tag_exists", "tag with this name already exists")
+)
+
+type TagService struct {
+	base
+}
+
+func NewTagService() *TagService {
+	return &TagService{}
+}
+
+// WithContext привязывает сервис к контексту запроса
+func (s *TagService) WithContext(ctx context.Context) *TagService {
+	s.ctx = ctx
+	return s
+}
+
+func (s *TagService) GetAll(accountID int64) ([]models.Tag, error) {
+	ctx, span := s.start("TagService.GetAll")
+	defer span.End()
+	tags := []models.Tag{}
+	err := orm(ctx).Model(&models.Tag{}).Where("account_id = ?", accountID).Order("name, id").Find(&tags).Error
+	return tags, err
+}
+
+func (s *TagService) Create(accountID int64, name string) (*models.Tag, error) {
+	ctx, span := s.start("TagService.Create")
+	defer span.End()
+	tag := &models.Tag{AccountID: accountID, Name: name}
+	result := orm(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(tag)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrTagExists
+	}
+	return tag, nil
+}
+
+func (s *TagService) Rename(accountID, tagID int64, name string) (*models.Tag, error) {
+	ctx, span := s.start("TagService.Rename")
+	defer span.End()
+	// занятое имя отсекает уникальный индекс idx_tags_account_name
+	result := orm(ctx).Model(&models.Tag{}).Where("id = ? AND account_id = ?", tagID, accountID).Update("name", name)
+	if result.Error != nil {
+		return nil, duplicate(result.Error, ErrTagExists)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrTagNotFound
+	}
+	var tag models.Tag
+	if err := orm(ctx).First(&tag, tagID).Error; err != nil {
+		return nil, notFound(err, ErrTagNotFound)
+	}
+	return &tag, nil
+}
+
+// Delete удаляет метку и снимает ее со всех желаний
+func (s *TagService) Delete(accountID, tagID int64) error {
+	ctx, span := s.start("TagService.Delete")
+	defer span.End()
+	return orm(ctx).Transaction(func(tx *gorm.DB) error {
+		// сначала связи с желаниями: на метку ссылается wish_item_tags
+		err := tx.Where("tag_id IN (?)", tx.Model(&models.Tag{}).Select("id").Where("id = ? AND account_id = ?", tagID, accountID)).
+			Delete(&models.WishItemTag{}).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND account_id = ?", tagID, accountID).Delete(&models.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTagNotFound
+		}
+		return nil
+	})
+}
+
+// replaceTags ставит желанию ровно метки tagIDs; все они должны принадлежать владельцу желания
+func replaceTags(tx *gorm.DB, wishItemID, ownerID int64, tagIDs []int64) error {
+	tagIDs = slices.Compact(slices.Sorted(slices.Values(tagIDs)))
+	if len(tagIDs) > 0 {
+		var owned int64
+		err := tx.Model(&models.Tag{}).Where("id IN ? AND account_id = ?", tagIDs, ownerID).Count(&owned).Error
+		if err != nil {
+			return err
+		}
+		if owned != int64(len(tagIDs)) {
+			return ErrTagNotFound
+		}
+	}
+	if err := tx.Where("wish_item_id = ?", wishItemID).Delete(&models.WishItemTag{}).Error; err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	links := make([]models.WishItemTag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		links = append(links, models.WishItemTag{WishItemID: wishItemID, TagID: tagID})
+	}
+	return tx.Create(&links).Error
+}
+
+// fillTags проставляет желаниям их метки; желания без меток получают пустой список
+func fillTags(tx *gorm.DB, wishItems []models.WishItem) error {
+	if len(wishItems) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(wishItems))
+	for _, item := range wishItems {
+		ids = append(ids, item.ID)
+	}
+
+	var rows []struct {
+		WishItemID int64
+		models.Tag
+	}
+	err := tx.Model(&models.Tag{}).
+		Select("wish_item_tags.wish_item_id, tags.*").
+		Joins("JOIN wish_item_tags ON wish_item_tags.tag_id = tags.id").
+		Where("wish_item_tags.wish_item_id IN ?", ids).
+		Order("tags.name, tags.id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	tags := make(map[int64][]models.Tag, len(rows))
+	for _, row := range rows {
+		tags[row.WishItemID] = append(tags[row.WishItemID], row.Tag)
+	}
+	for i := range wishItems {
+		wishItems[i].Tags = tags[wishItems[i].ID]
+		if wishItems[i].Tags == nil {
+			wishItems[i].Tags = []models.Tag{}
+		}
+	}
+	return nil
+}
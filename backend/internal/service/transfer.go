@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"wishlist-go/internal/apperr"
+	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/metrics"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSameList       = apperr.New(apperr.KindValidation, "same_list", "wishes are already in this list")
+	ErrMoveOtherOwner = apperr.New(apperr.KindConflict, "move_to_other_owner", "wishes can only be moved between lists of the same owner")
+)
+
+// MoveTo переносит желания в список target, сохраняя id, резервы, взносы и метки.
+// Резервы и взносы гости делали для конкретного человека, поэтому переносить можно только между списками одного владельца.
+func (s *WishItemService) MoveTo(target *models.WishList, ids []int64) ([]models.WishItem, error) {
+	ctx, span := s.start("WishItemService.MoveTo")
+	defer span.End()
+	var moved []int64
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		items, err := s.lockForTransfer(tx, target, ids)
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, target.ShareCode)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			if item.OwnerID != target.OwnerID {
+				return ErrMoveOtherOwner
+			}
+			err := tx.Model(&models.WishItem{}).Where("id = ?", item.ID).Updates(map[string]any{
+				"wish_list_code": target.ShareCode,
+				"position":       position + float64(i)*positionStep,
+			}).Error
+			if err != nil {
+				return err
+			}
+			moved = append(moved, item.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transferred(ctx, target, moved)
+}
+
+// CopyTo создает в списке target копии желаний. Копия - новое желание: статус pending, без резервов и взносов;
+// метки копируются, только если у списков один владелец, потому что метки принадлежат аккаунту.
+func (s *WishItemService) CopyTo(target *models.WishList, ids []int64) ([]models.WishItem, error) {
+	ctx, span := s.start("WishItemService.CopyTo")
+	defer span.End()
+	var copied []int64
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		items, err := s.lockForTransfer(tx, target, ids)
+		if err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, target.ShareCode)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			wishItem := &models.WishItem{
+				WishListCode:   target.ShareCode,
+				OwnerID:        target.OwnerID,
+				Name:           item.Name,
+				Priority:       item.Priority,
+				Status:         "pending",
+				MarketLink:     item.MarketLink,
+				MarketPicture:  item.MarketPicture,
+				MarketPrice:    item.MarketPrice,
+				MarketCurrency: item.MarketCurrency,
+				MarketQuantity: item.MarketQuantity,
+				Notes:          item.Notes,
+				Position:       position + float64(i)*positionStep,
+			}
+			if err := tx.Create(wishItem).Error; err != nil {
+				return err
+			}
+			if item.OwnerID == target.OwnerID {
+				err := tx.Exec("INSERT INTO wish_item_tags (wish_item_id, tag_id) SELECT ?, tag_id FROM wish_item_tags WHERE wish_item_id = ?",
+					wishItem.ID, item.ID).Error
+				if err != nil {
+					return err
+				}
+			}
+			copied = append(copied, wishItem.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.WishItemsCreated.Add(float64(len(copied)))
+	return transferred(ctx, target, copied)
+}
+
+// lockForTransfer блокирует желания ids списка s.WishList в их порядке в списке; в target они встанут так же
+func (s *WishItemService) lockForTransfer(tx *gorm.DB, target *models.WishList, ids []int64) ([]models.WishItem, error) {
+	if target.ShareCode == s.WishList {
+		return nil, ErrSameList
+	}
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	var items []models.WishItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ? AND wish_list_code = ?", ids, s.WishList).
+		Order("position, id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != len(ids) {
+		return nil, ErrWishItemNotFound
+	}
+	return items, nil
+}
+
+// transferred читает перенесенные желания уже из target вместе с метками
+func transferred(ctx context.Context, target *models.WishList, ids []int64) ([]models.WishItem, error) {
+	items := []models.WishItem{}
+	err := orm(ctx).Model(&models.WishItem{}).
+		Where("id IN ? AND wish_list_code = ?", ids, target.ShareCode).
+		Order("position, id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, fillTags(orm(ctx), items)
+}
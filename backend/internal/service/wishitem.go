@@ -1,13 +1,21 @@
 package service
 
 import (
-	"wishlist-go/internal/db"
+	"context"
+	"strings"
+	"wishlist-go/internal/apperr"
 	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/metrics"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var ErrWishItemNotFound = apperr.New(apperr.KindNotFound, "wish_item_not_found", "wish item not found")
+
 type WishItemService struct {
+	base
+
 	WishList       uuid.UUID
 	Owner          *int64
 	Name           *string
@@ -18,25 +26,115 @@ type WishItemService struct {
 	MarketPrice    *float64
 	MarketCurrency *string
 	MarketQuantity *int
+	Notes          *string
+	TagIDs         *[]int64 // nil - метки не меняются, пустой список - снять все
 }
 
 func NewWishItemService() *WishItemService {
 	return &WishItemService{}
 }
 
-func (s *WishItemService) GetAll(limit int, offset int) ([]models.WishItem, error) {
-	var wishItems []models.WishItem
-	err := db.ORM.Model(&models.WishItem{}).Where("wish_list_code = ?", s.WishList).Limit(limit).Offset(offset).Find(&wishItems).Error
-	return wishItems, err
+// WithContext привязывает сервис к контексту запроса
+func (s *WishItemService) WithContext(ctx context.Context) *WishItemService {
+	s.ctx = ctx
+	return s
+}
+
+// wishItemSorts - поля для параметра sort в выдаче желаний
+var wishItemSorts = map[string]sortKey[models.WishItem]{
+	"position": {column: "position", value: func(w *models.WishItem) any { return w.Position }, parse: parseAs[float64]},
+	"created":  {column: "created_at", value: func(w *models.WishItem) any { return w.CreatedAt }, parse: parseAs[int64]},
+	"priority": {column: "priority", value: func(w *models.WishItem) any { return w.Priority }, parse: parseAs[int]},
+	"price":    {column: "market_price", value: func(w *models.WishItem) any { return w.MarketPrice }, parse: parseAs[float64]},
+	"name":     {column: "name", value: func(w *models.WishItem) any { return w.Name }, parse: parseAs[string]},
+}
+
+// searchVector должно совпадать с выражением индекса idx_wish_items_search, иначе индекс не используется.
+// Конфигурация russian стеммит и кириллицу, и латиницу.
+const searchVector = "to_tsvector('russian', wish_items.name || ' ' || wish_items.notes)"
+
+// WishItemFilter - условия выборки желаний; пустые поля не ограничивают выдачу
+type WishItemFilter struct {
+	Query    string // полнотекстовый поиск по названию и заметкам
+	Statuses []string
+	PriceMin *float64
+	PriceMax *float64
+	Currency string
+	Priority *int
+	TagIDs   []int64 // желания хотя бы с одной из меток
+}
+
+func (f WishItemFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Query != "" {
+		query = query.Where(searchVector+" @@ websearch_to_tsquery('russian', ?)", f.Query)
+	}
+	if len(f.Statuses) > 0 {
+		query = query.Where("wish_items.status IN ?", f.Statuses)
+	}
+	if f.PriceMin != nil {
+		query = query.Where("wish_items.market_price >= ?", *f.PriceMin)
+	}
+	if f.PriceMax != nil {
+		query = query.Where("wish_items.market_price <= ?", *f.PriceMax)
+	}
+	if f.Currency != "" {
+		query = query.Where("wish_items.market_currency = ?", f.Currency)
+	}
+	if f.Priority != nil {
+		query = query.Where("wish_items.priority = ?", *f.Priority)
+	}
+	if len(f.TagIDs) > 0 {
+		query = query.Where("wish_items.id IN (?)",
+			query.Session(&gorm.Session{NewDB: true}).Model(&models.WishItemTag{}).Select("wish_item_id").Where("tag_id IN ?", f.TagIDs))
+	}
+	return query
+}
+
+func (s *WishItemService) GetAll(filter WishItemFilter, page PageRequest) (*Page[models.WishItem], error) {
+	ctx, span := s.start("WishItemService.GetAll")
+	defer span.End()
+	query := orm(ctx).Model(&models.WishItem{}).Where("wish_items.wish_list_code = ?", s.WishList)
+	result, err := paginate(filter.apply(query), "wish_items", page, wishItemSorts, "position", func(w *models.WishItem) int64 { return w.ID })
+	if err != nil {
+		return nil, err
+	}
+	return result, fillTags(orm(ctx), result.Items)
+}
+
+// Search ищет желания во всех списках пользователя: собственных и тех, где он участник.
+// Ручной порядок между списками не имеет смысла, поэтому по умолчанию выдача идет по дате создания.
+func (s *WishItemService) Search(accountID int64, filter WishItemFilter, page PageRequest) (*Page[models.WishItem], error) {
+	ctx, span := s.start("WishItemService.Search")
+	defer span.End()
+	lists := orm(ctx).Model(&models.WishList{}).Select("share_code").
+		Where("owner_id = ? OR id IN (?)", accountID,
+			orm(ctx).Model(&models.WishListMember{}).Select("wish_list_id").Where("account_id = ?", accountID))
+	query := orm(ctx).Model(&models.WishItem{}).Where("wish_items.wish_list_code IN (?)", lists)
+	result, err := paginate(filter.apply(query), "wish_items", page, wishItemSorts, "created", func(w *models.WishItem) int64 { return w.ID })
+	if err != nil {
+		return nil, err
+	}
+	return result, fillTags(orm(ctx), result.Items)
 }
 
 func (s *WishItemService) Get(id int64) (*models.WishItem, error) {
+	ctx, span := s.start("WishItemService.Get")
+	defer span.End()
 	var wishItem *models.WishItem
-	err := db.ORM.Model(&models.WishItem{}).Where("id = ? AND wish_list_code = ?", id, s.WishList).First(&wishItem).Error
-	return wishItem, err
+	err := orm(ctx).Model(&models.WishItem{}).Where("id = ? AND wish_list_code = ?", id, s.WishList).First(&wishItem).Error
+	if err != nil {
+		return nil, notFound(err, ErrWishItemNotFound)
+	}
+	items := []models.WishItem{*wishItem}
+	if err := fillTags(orm(ctx), items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
 }
 
 func (s *WishItemService) Create() (*models.WishItem, error) {
+	ctx, span := s.start("WishItemService.Create")
+	defer span.End()
 	wishItem := &models.WishItem{
 		WishListCode:   s.WishList,
 		OwnerID:        *s.Owner,
@@ -49,16 +147,35 @@ func (s *WishItemService) Create() (*models.WishItem, error) {
 		MarketCurrency: *s.MarketCurrency,
 		MarketQuantity: *s.MarketQuantity,
 	}
+	if s.Notes != nil {
+		wishItem.Notes = *s.Notes
+	}
 
-	err := db.ORM.Model(&models.WishItem{}).Create(wishItem).Error
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := nextPosition(tx, s.WishList)
+		if err != nil {
+			return err
+		}
+		wishItem.Position = position
+		if err := tx.Model(&models.WishItem{}).Create(wishItem).Error; err != nil {
+			return err
+		}
+		if s.TagIDs == nil {
+			return nil
+		}
+		return replaceTags(tx, wishItem.ID, wishItem.OwnerID, *s.TagIDs)
+	})
 	if err != nil {
 		return nil, err
 	}
+	metrics.WishItemsCreated.Inc()
 
 	return s.Get(wishItem.ID)
 }
 
 func (s *WishItemService) Update(id int64) (*models.WishItem, error) {
+	ctx, span := s.start("WishItemService.Update")
+	defer span.End()
 	updates := make(map[string]interface{})
 
 	if s.Name != nil {
@@ -85,12 +202,65 @@ func (s *WishItemService) Update(id int64) (*models.WishItem, error) {
 	if s.MarketQuantity != nil {
 		updates["market_quantity"] = *s.MarketQuantity
 	}
+	if s.Notes != nil {
+		updates["notes"] = *s.Notes
+	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && s.TagIDs == nil {
 		return s.Get(id)
 	}
 
-	err := db.ORM.Model(&models.WishItem{}).Where("id = ? AND wish_list_code = ?", id, s.WishList).Updates(updates).Error
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		wish, err := lockWish(tx, id, s.WishList)
+		if err != nil {
+			return err
+		}
+		if s.MarketCurrency != nil && !strings.EqualFold(*s.MarketCurrency, wish.MarketCurrency) {
+			// взносы хранятся в валюте желания, смешивать их при подсчете суммы нельзя
+			funded, err := fundedAmount(tx, wish.ID)
+			if err != nil {
+				return err
+			}
+			if funded > 0 {
+				return ErrCurrencyHasContributions
+			}
+		}
+		if s.MarketQuantity != nil {
+			reserved, err := reservedQuantity(tx, wish.ID)
+			if err != nil {
+				return err
+			}
+			if reserved > max(*s.MarketQuantity, 1) {
+				return ErrBelowReserved
+			}
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&models.WishItem{}).Where("id = ?", wish.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if s.Status != nil {
+			wish.Status = *s.Status
+		}
+		if s.MarketPrice != nil {
+			// с новой ценой собранных взносов может стать достаточно или, наоборот, мало
+			wish.MarketPrice = *s.MarketPrice
+			if err := syncFundedStatus(tx, wish); err != nil {
+				return err
+			}
+		}
+		if s.MarketQuantity != nil {
+			// с новым количеством желание может стать полностью зарезервированным или снова свободным
+			wish.MarketQuantity = *s.MarketQuantity
+			if err := syncWishStatus(tx, wish); err != nil {
+				return err
+			}
+		}
+		if s.TagIDs == nil {
+			return nil
+		}
+		return replaceTags(tx, wish.ID, wish.OwnerID, *s.TagIDs)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -100,5 +270,32 @@ func (s *WishItemService) Update(id int64) (*models.WishItem, error) {
 }
 
 func (s *WishItemService) Delete(id int64) error {
-	return db.ORM.Model(&models.WishItem{}).Where("id = ? AND wish_list_code = ?", id, s.WishList).Delete(&models.WishItem{}).Error
+	ctx, span := s.start("WishItemService.Delete")
+	defer span.End()
+	return orm(ctx).Transaction(func(tx *gorm.DB) error {
+		err := deleteWishDependents(tx, tx.Model(&models.WishItem{}).Select("id").Where("id = ? AND wish_list_code = ?", id, s.WishList))
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&models.WishItem{}).Where("id = ? AND wish_list_code = ?", id, s.WishList).Delete(&models.WishItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWishItemNotFound
+		}
+		return nil
+	})
+}
+
+// deleteWishDependents удаляет метки, резервы и взносы желаний из подзапроса ids:
+// они ссылаются на wish_items, поэтому удаляются раньше самих желаний
+func deleteWishDependents(tx *gorm.DB, ids *gorm.DB) error {
+	if err := tx.Where("wish_item_id IN (?)", ids).Delete(&models.WishItemTag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("wish_id IN (?)", ids).Delete(&models.WishReservation{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("wish_id IN (?)", ids).Delete(&models.WishContribution{}).Error
 }
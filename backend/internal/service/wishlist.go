@@ -1,14 +1,22 @@
 package service
 
 import (
+	"context"
+	"time"
+	"wishlist-go/internal/apperr"
 	"wishlist-go/internal/db"
 	"wishlist-go/internal/db/models"
+	"wishlist-go/internal/metrics"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var ErrWishlistNotFound = apperr.New(apperr.KindNotFound, "wishlist_not_found", "wishlist not found")
+
 type WishlistService struct {
+	base
+
 	orm *gorm.DB
 }
 
@@ -16,40 +24,122 @@ func NewWishlistService() *WishlistService {
 	return &WishlistService{orm: db.ORM}
 }
 
+// WithContext привязывает сервис к контексту запроса
+func (s *WishlistService) WithContext(ctx context.Context) *WishlistService {
+	s.ctx = ctx
+	return s
+}
+
 type WishlistInsert struct {
-	Name        *string
-	Description *string
-	Owner       *int64
+	Name               *string
+	Description        *string
+	Owner              *int64
+	ReservationTTLDays *int
+	OccasionType       *string
+	OccasionDate       *time.Time // нулевое время при обновлении убирает дату
+	OccasionRecurrence *string
+	Visibility         *string
+	Passphrase         *string // пустая строка - без защиты
 }
 
-func (s *WishlistService) GetAllByOwner(ownerTelegramId int64, limit int, offset int) ([]models.WishList, error) {
-	var wishlists []models.WishList
-	err := db.ORM.Model(&models.WishList{}).Debug().Where("owner_id = ?", ownerTelegramId).Limit(limit).Offset(offset).Find(&wishlists).Error
-	return wishlists, err
+// wishlistSorts - поля для параметра sort в выдаче списков
+var wishlistSorts = map[string]sortKey[models.WishList]{
+	"created": {column: "created_at", value: func(w *models.WishList) any { return w.CreatedAt }, parse: parseAs[int64]},
+	"name":    {column: "name", value: func(w *models.WishList) any { return w.Name }, parse: parseAs[string]},
+}
+
+// GetAllForAccount возвращает собственные списки пользователя и списки, в которых он участник
+func (s *WishlistService) GetAllForAccount(accountTelegramId int64, page PageRequest) (*Page[models.WishList], error) {
+	ctx, span := s.start("WishlistService.GetAllForAccount")
+	defer span.End()
+	query := orm(ctx).Model(&models.WishList{}).
+		Where("wish_lists.owner_id = ? OR wish_lists.id IN (?)", accountTelegramId,
+			orm(ctx).Model(&models.WishListMember{}).Select("wish_list_id").Where("account_id = ?", accountTelegramId))
+	return paginate(query, "wish_lists", page, wishlistSorts, "created", func(w *models.WishList) int64 { return w.ID })
+}
+
+// GetPublicByOwner возвращает списки, которые владелец показывает в публичном профиле
+// вместе с действующей ссылкой профиля; отозвав ее, владелец убирает список из профиля
+func (s *WishlistService) GetPublicByOwner(ownerTelegramId int64, page PageRequest) (*Page[models.WishList], error) {
+	ctx, span := s.start("WishlistService.GetPublicByOwner")
+	defer span.End()
+	query := orm(ctx).Model(&models.WishList{}).
+		Where("wish_lists.owner_id = ? AND wish_lists.visibility = ?", ownerTelegramId, models.VisibilityPublic).
+		Where("EXISTS (?)", publicTokens(orm(ctx)).Select("1").Where("share_tokens.wish_list_id = wish_lists.id"))
+	return paginate(query, "wish_lists", page, wishlistSorts, "created", func(w *models.WishList) int64 { return w.ID })
 }
 
 func (s *WishlistService) Get(uuid uuid.UUID) (*models.WishList, error) {
+	ctx, span := s.start("WishlistService.Get")
+	defer span.End()
 	var wishlist *models.WishList
-	err := db.ORM.Model(&models.WishList{}).Where("share_code = ?", uuid).First(&wishlist).Error
-	return wishlist, err
+	err := orm(ctx).Model(&models.WishList{}).Where("share_code = ?", uuid).First(&wishlist).Error
+	if err != nil {
+		return nil, notFound(err, ErrWishlistNotFound)
+	}
+	return wishlist, nil
 }
 
 func (s *WishlistService) Create(insert *WishlistInsert) (*models.WishList, error) {
+	ctx, span := s.start("WishlistService.Create")
+	defer span.End()
 	// генерируем уникальный share_code
 	shareCode := uuid.New()
-	err := db.ORM.Model(&models.WishList{}).Create(&models.WishList{
+	wishlist := &models.WishList{
 		Name:        *insert.Name,
 		Description: *insert.Description,
 		OwnerID:     *insert.Owner,
 		ShareCode:   shareCode,
-	}).Error
+	}
+	if insert.ReservationTTLDays != nil {
+		wishlist.ReservationTTLDays = *insert.ReservationTTLDays
+	}
+	if insert.OccasionType != nil {
+		wishlist.OccasionType = *insert.OccasionType
+	}
+	if insert.OccasionDate != nil && !insert.OccasionDate.IsZero() {
+		wishlist.OccasionDate = insert.OccasionDate
+	}
+	wishlist.Visibility = models.VisibilityLink
+	if insert.Visibility != nil {
+		wishlist.Visibility = *insert.Visibility
+	}
+	wishlist.OccasionRecurrence = "none"
+	if insert.OccasionRecurrence != nil {
+		wishlist.OccasionRecurrence = *insert.OccasionRecurrence
+	}
+	// хеш считается до транзакции: при ошибке список не должен остаться созданным без защиты
+	if insert.Passphrase != nil && *insert.Passphrase != "" {
+		hash, err := HashPassphrase(*insert.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		wishlist.PassphraseHash = hash
+		wishlist.Protected = true
+	}
+	// вместе со списком выпускаем первую ссылку для гостей
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.WishList{}).Create(wishlist).Error; err != nil {
+			return err
+		}
+		if _, err := createShareToken(tx, wishlist.ID, wishlist.OwnerID, "default", 0); err != nil {
+			return err
+		}
+		if wishlist.Visibility != models.VisibilityPublic {
+			return nil
+		}
+		return ensurePublicToken(tx, wishlist)
+	})
 	if err != nil {
 		return nil, err
 	}
+	metrics.WishlistsCreated.Inc()
 	return s.Get(shareCode)
 }
 
 func (s *WishlistService) Update(shareCode uuid.UUID, patch WishlistInsert) (*models.WishList, error) {
+	ctx, span := s.start("WishlistService.Update")
+	defer span.End()
 	updates := make(map[string]interface{})
 
 	if patch.Name != nil {
@@ -58,12 +148,56 @@ func (s *WishlistService) Update(shareCode uuid.UUID, patch WishlistInsert) (*mo
 	if patch.Description != nil {
 		updates["description"] = patch.Description
 	}
+	if patch.ReservationTTLDays != nil {
+		updates["reservation_ttl_days"] = *patch.ReservationTTLDays
+	}
+	if patch.OccasionType != nil {
+		updates["occasion_type"] = *patch.OccasionType
+	}
+	if patch.OccasionDate != nil {
+		if patch.OccasionDate.IsZero() {
+			updates["occasion_date"] = nil
+		} else {
+			updates["occasion_date"] = *patch.OccasionDate
+		}
+	}
+	if patch.OccasionRecurrence != nil {
+		updates["occasion_recurrence"] = *patch.OccasionRecurrence
+	}
+	if patch.Visibility != nil {
+		updates["visibility"] = *patch.Visibility
+	}
+	if patch.Passphrase != nil {
+		updates["passphrase_hash"] = ""
+		updates["protected"] = false
+		if *patch.Passphrase != "" {
+			hash, err := HashPassphrase(*patch.Passphrase)
+			if err != nil {
+				return nil, err
+			}
+			updates["passphrase_hash"] = hash
+			updates["protected"] = true
+		}
+	}
 
 	if len(updates) == 0 {
 		return s.Get(shareCode)
 	}
 
-	err := db.ORM.Model(&models.WishList{}).Where("share_code = ?", shareCode).Updates(updates).Error
+	err := orm(ctx).Transaction(func(tx *gorm.DB) error {
+		var wishlist models.WishList
+		if err := tx.Where("share_code = ?", shareCode).First(&wishlist).Error; err != nil {
+			return notFound(err, ErrWishlistNotFound)
+		}
+		if err := tx.Model(&wishlist).Updates(updates).Error; err != nil {
+			return err
+		}
+		// ссылку профиля выпускаем при публикации, чтобы чтение профиля ничего не записывало
+		if patch.Visibility == nil || *patch.Visibility != models.VisibilityPublic {
+			return nil
+		}
+		return ensurePublicToken(tx, &wishlist)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -71,17 +205,38 @@ func (s *WishlistService) Update(shareCode uuid.UUID, patch WishlistInsert) (*mo
 }
 
 func (s *WishlistService) Delete(shareCode uuid.UUID) error {
-	return db.ORM.Model(&models.WishList{}).Where("share_code = ?", shareCode).Delete(&models.WishList{}).Error
-}
-
-func (s *WishlistService) CheckAccess(ownerTelegramId int64, shareCode uuid.UUID) bool {
-	var wishlistExists bool
-	err := db.ORM.Model(&models.WishList{}).
-		Select("count(*) > 0").
-		Where("owner_id = ? AND share_code = ?", ownerTelegramId, shareCode).
-		Find(&wishlistExists).Error
+	ctx, span := s.start("WishlistService.Delete")
+	defer span.End()
+	wishlist, err := s.Get(shareCode)
 	if err != nil {
-		return false
+		return err
 	}
-	return wishlistExists
+	return orm(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wish_list_id = ?", wishlist.ID).Delete(&models.WishListMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wish_list_id = ?", wishlist.ID).Delete(&models.WishListInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wish_list_id = ?", wishlist.ID).Delete(&models.WishListAllowedUser{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wish_list_id = ?", wishlist.ID).Delete(&models.ShareToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wish_list_id = ?", wishlist.ID).Delete(&models.WishListView{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wish_list_id = ?", wishlist.ID).Delete(&models.OccasionReminder{}).Error; err != nil {
+			return err
+		}
+		items := tx.Model(&models.WishItem{}).Select("id").Where("wish_list_code = ?", wishlist.ShareCode)
+		if err := deleteWishDependents(tx, items); err != nil {
+			return err
+		}
+		if err := tx.Where("wish_list_code = ?", wishlist.ShareCode).Delete(&models.WishItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WishList{}).Where("id = ?", wishlist.ID).Delete(&models.WishList{}).Error
+	})
 }
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const apiURL = "https://api.telegram.org"
+
+// Bot - минимальный клиент Bot API для отправки уведомлений пользователям
+type Bot struct {
+	token  string
+	client *http.Client
+}
+
+func NewBot(token string) *Bot {
+	return &Bot{
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (b *Bot) SendMessage(chatID int64, text string) error {
+	if b.token == "" {
+		return fmt.Errorf("telegram bot token is not configured")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.client.Post(fmt.Sprintf("%s/bot%s/sendMessage", apiURL, b.token), "application/json", bytes.NewReader(body))
+	if err != nil {
+		// url.Error содержит адрес запроса вместе с токеном бота
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode telegram response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram api error: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/version"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Setup настраивает глобальный TracerProvider по секции tracing.
+// exporter == nil - OTLP/HTTP на tracing.endpoint; без адреса остается no-op провайдер и спаны никуда не уходят.
+// Для проверок можно передать tracetest.NewInMemoryExporter().
+// Возвращает функцию, которая досылает накопленные спаны при остановке.
+func Setup(cfg *config.AppConfigStruct, exporter sdktrace.SpanExporter) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// переданный экспортер (в проверках) получает спаны сразу, OTLP - пачками
+	var processor sdktrace.TracerProviderOption
+	if exporter == nil {
+		if cfg.Tracing.Endpoint == "" {
+			return func(context.Context) error { return nil }, nil
+		}
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Tracing.Endpoint)}
+		if cfg.Tracing.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		var err error
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		processor = sdktrace.WithBatcher(exporter)
+	} else {
+		processor = sdktrace.WithSyncer(exporter)
+	}
+
+	res, err := resource.New(context.Background(),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.Tracing.ServiceName),
+			semconv.ServiceVersion(version.Get().Version),
+			semconv.DeploymentEnvironment(cfg.Sentry.Environment),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		processor,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
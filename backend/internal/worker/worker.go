@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+	"wishlist-go/internal/config"
+	"wishlist-go/internal/service"
+	"wishlist-go/internal/telegram"
+
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// напоминания о поводах считаются по дням, чаще раза в час проверять незачем
+	occasionCheckInterval = time.Hour
+)
+
+var tracer = otel.Tracer("wishlist-go/internal/worker")
+
+// время последнего прохода фоновых задач, его проверяет /readyz
+var heartbeat atomic.Int64
+
+// Heartbeat возвращает время последнего прохода; нулевое время - воркер еще не запускался
+func Heartbeat() time.Time {
+	if ts := heartbeat.Load(); ts > 0 {
+		return time.Unix(0, ts)
+	}
+	return time.Time{}
+}
+
+// Worker периодически выполняет фоновые задачи: напоминания и снятие просроченных резервов
+type Worker struct {
+	bot                  *telegram.Bot
+	interval             time.Duration
+	reminderBefore       time.Duration
+	occasionReminderDays []int
+	lastOccasionCheck    time.Time
+}
+
+func New(bot *telegram.Bot) *Worker {
+	// значения по умолчанию и проверку берет на себя config
+	return &Worker{
+		bot:                  bot,
+		interval:             config.Config.Worker.Interval,
+		reminderBefore:       config.Config.Worker.ReminderBefore,
+		occasionReminderDays: config.Config.Worker.OccasionReminderDays,
+	}
+}
+
+// Run блокируется до отмены контекста
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// начатый проход доводим до конца, иначе напоминание уйдет, а отметка о нем - нет
+		w.tick(context.WithoutCancel(ctx))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) tick(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "worker.tick")
+	defer span.End()
+	defer heartbeat.Store(time.Now().UnixNano())
+	if err := w.remindReservations(ctx); err != nil {
+		slog.Error("failed to send reservation reminders", "error", err)
+	}
+	if err := w.releaseExpiredReservations(ctx); err != nil {
+		slog.Error("failed to release expired reservations", "error", err)
+	}
+	if time.Since(w.lastOccasionCheck) >= occasionCheckInterval {
+		w.lastOccasionCheck = time.Now()
+		if err := w.remindOccasions(ctx); err != nil {
+			slog.Error("failed to send occasion reminders", "error", err)
+		}
+	}
+}
+
+func (w *Worker) remindReservations(ctx context.Context) error {
+	reservationService := service.NewReservationService().WithContext(ctx)
+	reservations, err := reservationService.DueForReminder(w.reminderBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		expiresAt := time.Unix(*reservation.ExpiresAt, 0)
+		text := fmt.Sprintf(
+			"Напоминание: резерв на «%s» истекает %s. Если подарок еще актуален, продлите резерв в приложении, иначе он будет снят.",
+			reservation.Wish.Name, expiresAt.Format("02.01.2006 15:04"),
+		)
+		if err := w.bot.SendMessage(reservation.ReserverID, text); err != nil {
+			slog.Warn("failed to remind reserver", "reserver_id", reservation.ReserverID, "error", err)
+			continue
+		}
+		if err := reservationService.MarkReminded(reservation.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Worker) releaseExpiredReservations(ctx context.Context) error {
+	released, err := service.NewReservationService().WithContext(ctx).ReleaseExpired()
+	for _, reservation := range released {
+		text := fmt.Sprintf("Резерв на «%s» истек и был снят.", reservation.Wish.Name)
+		if err := w.bot.SendMessage(reservation.ReserverID, text); err != nil {
+			slog.Warn("failed to notify reserver", "reserver_id", reservation.ReserverID, "error", err)
+		}
+	}
+	return err
+}
+
+func (w *Worker) remindOccasions(ctx context.Context) error {
+	occasionService := service.NewOccasionService().WithContext(ctx)
+	targets, err := occasionService.DueReminders(w.occasionReminderDays)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		text := fmt.Sprintf(
+			"До повода списка «%s» осталось дней: %d (%s). Загляните в список, пока подарки еще не разобрали.",
+			target.WishList.Name, target.DaysLeft, target.Date.Format("02.01.2006"),
+		)
+		if err := w.bot.SendMessage(target.AccountID, text); err != nil {
+			slog.Warn("failed to send occasion reminder", "account_id", target.AccountID, "wishlist_id", target.WishList.ID, "error", err)
+			continue
+		}
+		if err := occasionService.MarkReminded(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,19 +1,61 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"wishlist-go/internal/api"
+	"wishlist-go/internal/api/middleware"
+	"wishlist-go/internal/api/openapi"
 	"wishlist-go/internal/config"
 	"wishlist-go/internal/db"
+	"wishlist-go/internal/logging"
+	"wishlist-go/internal/monitoring"
+	"wishlist-go/internal/telegram"
+	"wishlist-go/internal/tracing"
+	"wishlist-go/internal/worker"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
 	configPath := flag.String("config", "config.yaml", "Path to config file")
+	checkOpenAPI := flag.Bool("check-openapi", false, "Check that openapi.json describes every route and exit")
+	genTypes := flag.String("gen-types", "", "Write TypeScript types generated from openapi.json to this file and exit")
 	flag.Parse()
 
+	if *genTypes != "" {
+		types, err := openapi.TypeScript()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*genTypes, types, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// проверка не требует ни конфига, ни базы: маршруты регистрируются без них
+	if *checkOpenAPI {
+		gin.SetMode(gin.ReleaseMode)
+		router := gin.New()
+		api.Register(router)
+		if err := openapi.Verify(router.Routes()); err != nil {
+			log.Fatal(err)
+		}
+		log.Print("openapi.json matches the router")
+		return
+	}
+
 	// Загружаем конфигурацию и подключаемся к базе данных
 
 	err := config.LoadConfigFile(*configPath)
@@ -21,19 +63,107 @@ func main() {
 		log.Panicf("Failed to load config file %s: %v+", *configPath, err.Error())
 	}
 
+	logFile, err := logging.Setup(config.Config)
+	if err != nil {
+		log.Panicf("Failed to set up logging: %v", err)
+	}
+	defer logFile.Close()
+
+	// без DSN отчеты об ошибках просто не отправляются, сервер работает и так
+	if err := monitoring.InitSentry(config.Config, nil); err != nil {
+		slog.Error("failed to init sentry", "error", err)
+	}
+	defer monitoring.Flush()
+
+	shutdownTracing, err := tracing.Setup(config.Config, nil)
+	if err != nil {
+		log.Panicf("Failed to set up tracing: %v", err)
+	}
+
 	err = db.ConnectDB()
 	if err != nil {
 		panic("Failed to connect to the database: " + err.Error())
 	}
 
-	router := gin.Default()
+	// SIGINT/SIGTERM отменяют контекст: останавливаем воркер и дожидаемся активных запросов
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// фоновые задачи: напоминания и снятие просроченных резервов
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		worker.New(telegram.NewBot(config.Config.Telegram.BotToken)).Run(ctx)
+	}()
+
+	router := gin.New()
 	router.Use(gin.Recovery())
-	router.Use(gin.Logger())
+	router.Use(middleware.Tracing(config.Config.Tracing.ServiceName))
+	router.Use(middleware.RequestID())
+	router.Use(middleware.AccessLog())
+	// Metrics снаружи Sentry: паника, превращенная Sentry в 500, тоже попадает в счетчики
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Sentry())
+	router.Use(middleware.Errors())
+
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	api.Register(router)
+	if err := openapi.Verify(router.Routes()); err != nil {
+		slog.Warn("openapi spec drift", "error", err)
+	}
+
+	serverConfig := config.Config.Server
+	server := &http.Server{
+		Addr:         net.JoinHostPort(serverConfig.Host, strconv.Itoa(serverConfig.Port)),
+		Handler:      router,
+		ReadTimeout:  serverConfig.ReadTimeout,
+		WriteTimeout: serverConfig.WriteTimeout,
+		IdleTimeout:  serverConfig.IdleTimeout,
+	}
 
-	api.PublicApi(router)
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", server.Addr, "tls", serverConfig.TLSCertFile != "")
+		if serverConfig.TLSCertFile != "" {
+			serverErr <- server.ListenAndServeTLS(serverConfig.TLSCertFile, serverConfig.TLSKeyFile)
+		} else {
+			serverErr <- server.ListenAndServe()
+		}
+	}()
 
-	// Start the server
-	if err := router.Run(":8080"); err != nil {
-		panic(err)
+	var failed bool
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server failed", "error", err)
+			failed = true
+		}
+		stop()
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("graceful shutdown failed", "error", err)
+	}
+	select {
+	case <-workerDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("worker did not stop in time")
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+	}
+	if failed {
+		// os.Exit не выполняет defer
+		monitoring.Flush()
+		logFile.Close()
+		os.Exit(1)
 	}
 }
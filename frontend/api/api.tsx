@@ -1,8 +1,15 @@
 import {retrieveRawInitData} from '@telegram-apps/sdk';
 import {List, Wish} from './interfaces';
+import {operations} from './schema';
+import type {Operations, UpdateWishItemRequest, UpdateWishlistRequest} from './schema';
 
 
-const backendAPI = async (method: string, endpoint: string, body: object | null) => {
+// Вызов API по operationId из openapi.json: путь и тела проверяются по сгенерированной карте Operations,
+// поэтому расхождение со спецификацией ломает сборку фронтенда
+const backendAPI = async <K extends keyof Operations>(
+    operation: K, endpoint: Operations[K]["path"], body: Operations[K]["request"],
+): Promise<Operations[K]["response"]> => {
+    const method = operations[operation];
     let initDataRaw;
     try {
         initDataRaw = retrieveRawInitData()
@@ -27,7 +34,7 @@ const backendAPI = async (method: string, endpoint: string, body: object | null)
     });
 
     const response = await fetch(url, {
-        method: method.toUpperCase(),
+        method: method,
         headers: headers,
         body: method !== 'GET' ? JSON.stringify(body) : null
     }).catch((error) => {
@@ -38,48 +45,42 @@ const backendAPI = async (method: string, endpoint: string, body: object | null)
         throw new Error("No response from server");
     }
 
-    if (method != "DELETE") {
-        return response.json();
-    }
-
+    return response.json();
 };
 
-const AddToFavorites = async (id: string): Promise<List[]> => {
-    return await backendAPI("POST", `wishlist/${id}/favorite`, null);
-}
-
 // Получение всех списков
 const FetchLists = async (): Promise<List[]> => {
-    const response = await backendAPI("GET", "list", null);
+    const response = await backendAPI("getWishlists", "list", null);
     return response.wishlists || [];
 };
 
-// Получение "чужих" списков (избранное)
-const FetchFavorites = async (): Promise<List[]> => {
-    return await backendAPI("GET", "favorites", null);
-}
-
 // Создание нового списка
 const CreateWishlist = async (name: string, description: string): Promise<List> => {
-    const response = await backendAPI("POST", "list", {name: name, description: description});
+    const response = await backendAPI("createWishlist", "list", {name: name, description: description});
     return response.wishlist;
 }
 
 // Получение списка желаний по ID
 const FetchWishlist = async (id: string): Promise<Wish[]> => {
-    const response = await backendAPI("GET", `list/${id}/wishes`, null);
+    const response = await backendAPI("getWishItems", `list/${id}/wishes`, null);
     return response.wish_items || [];
 }
 
 // Редактирование списка
-const EditWishlist = async (id: string, fields: object): Promise<List> => {
-    const response = await backendAPI("PATCH", `list/${id}`, fields);
+const EditWishlist = async (id: string, fields: UpdateWishlistRequest): Promise<List> => {
+    const response = await backendAPI("updateWishlist", `list/${id}`, fields);
     return response.wishlist;
 }
 
 // Удаление списка
 const DeleteWishlist = async (id: string): Promise<void> => {
-    await backendAPI("DELETE", `list/${id}`, null);
+    await backendAPI("deleteWishlist", `list/${id}`, null);
+}
+
+// Ссылка для гостей: действующий токен "default", share_code открывает список только участникам
+const FetchShareToken = async (id: string): Promise<string | undefined> => {
+    const response = await backendAPI("getShareTokens", `list/${id}/share-tokens`, null);
+    return response.share_tokens?.find((token) => token.label === "default" && !token.revoked_at)?.token;
 }
 
 // Создание желания в списке
@@ -87,7 +88,7 @@ const CreateWish = async (wishlistId: string,
                           name: string, market_url: string, market_pic: string, market_price: number,
                           market_currency: string, market_quantity: number, priority: number): Promise<Wish> => {
 
-    const response = await backendAPI("POST", `list/${wishlistId}/wishes`,
+    const response = await backendAPI("createWishItem", `list/${wishlistId}/wishes`,
         {
             name: name, market_link: market_url, market_picture: market_pic, market_price: market_price,
             market_currency: market_currency, market_quantity: market_quantity, priority: priority
@@ -97,20 +98,20 @@ const CreateWish = async (wishlistId: string,
 
 // Получение конкретного желания из списка
 const FetchWish = async (wishlistId: string, wishId: string):Promise<Wish> => {
-    const response = await backendAPI("GET", `list/${wishlistId}/wishes/${wishId}`, null);
+    const response = await backendAPI("getWishItem", `list/${wishlistId}/wishes/${wishId}`, null);
     return response.wish_item;
 }
 
 // Редактирование желания в списке
-const EditWish = async (wishlistId: string, wishId: string, fields: object):Promise<Wish> => {
-    const response = await backendAPI("PATCH", `list/${wishlistId}/wishes/${wishId}`, fields);
+const EditWish = async (wishlistId: string, wishId: string, fields: UpdateWishItemRequest):Promise<Wish> => {
+    const response = await backendAPI("updateWishItem", `list/${wishlistId}/wishes/${wishId}`, fields);
     return response.wish_item;
 }
 
 // Удаление желания из списка
 const DeleteWish = async (wishlistId: string, wishId: string) :Promise<void> => {
-    await backendAPI("DELETE", `list/${wishlistId}/wishes/${wishId}`, null);
+    await backendAPI("deleteWishItem", `list/${wishlistId}/wishes/${wishId}`, null);
 }
 
-export {AddToFavorites, FetchLists, FetchFavorites, CreateWishlist, FetchWishlist, EditWishlist,
-    DeleteWishlist, CreateWish, FetchWish, EditWish, DeleteWish};
\ No newline at end of file
+export {FetchLists, CreateWishlist, FetchWishlist, EditWishlist,
+    DeleteWishlist, FetchShareToken, CreateWish, FetchWish, EditWish, DeleteWish};
\ No newline at end of file
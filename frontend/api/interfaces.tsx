@@ -1,29 +1,9 @@
-interface List {
-    id: number;
-    owner: number;
-    name: string;
-    description: string;
-    share_code: string;
-    created_at: number;
-    updated_at: number;
-}
+import type {WishItem, WishList} from "./schema";
 
-interface Wish {
-    id: number;
-    wishlist_code: string;
-    owner_id: number;
-    name: string;
-    priority: number;
-    status: string;
-    market_link: string;
-    market_picture: string;
-    market_price: number;
-    market_currency: string;
-    market_quantity: number;
-    created_at: number;
-    updated_at: number;
-}
+// типы приходят из openapi.json (api/schema.ts, make openapi-types); здесь только короткие имена для компонентов
+type List = WishList;
+type Wish = WishItem;
 
 interface Empty {}
 
-export {List, Wish, Empty}
\ No newline at end of file
+export type {List, Wish, Empty}
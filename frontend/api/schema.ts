@@ -0,0 +1,813 @@
+// Сгенерировано из backend/internal/api/openapi/openapi.json командой make openapi-types, не править вручную
+
+export interface Error {
+    error: {
+        /** Stable machine-readable code, e.g. wishlist_not_found */
+        code: string;
+        /** Localized by the Telegram user's language_code */
+        message: string;
+        /** Extra data, e.g. per-field validation errors */
+        details?: unknown;
+        request_id?: string;
+    };
+}
+
+export interface FieldError {
+    field: string;
+    rule: string;
+    param?: string;
+}
+
+export interface Message {
+    message: string;
+}
+
+export type Role = "viewer" | "editor" | "owner";
+
+export type Visibility = "private" | "link" | "invite" | "public";
+
+export interface WishList {
+    id: number;
+    owner: number;
+    name: string;
+    description: string;
+    /** Permanent list id, returned to members only */
+    share_code?: string;
+    /** Guest link the list was opened with */
+    share_token?: string;
+    /** Unix time, seconds */
+    created_at: number;
+    /** Unix time, seconds */
+    updated_at: number;
+    visibility: Visibility;
+    protected: boolean;
+    reservation_ttl_days?: number;
+    occasion_type?: "" | "birthday" | "wedding" | "new_year" | "other";
+    occasion_date?: string;
+    occasion_recurrence?: "none" | "yearly";
+    days_left?: number;
+}
+
+export interface WishItem {
+    id: number;
+    /** Returned to members only */
+    wishlist_code?: string;
+    owner_id: number;
+    name: string;
+    priority: number;
+    status: "pending" | "reserved" | "funded" | "purchased";
+    market_link: string;
+    market_picture: string;
+    market_price: number;
+    market_currency: string;
+    market_quantity: number;
+    /** Owner's notes: size, color, where to buy. Searched together with the name */
+    notes: string;
+    /** Manual order within the list, ascending */
+    position: number;
+    /** List owner's tags */
+    tags: Tag[];
+    /** Unix time, seconds */
+    created_at: number;
+    /** Unix time, seconds */
+    updated_at: number;
+    /** Guests and viewers only */
+    reserved_quantity?: number;
+    /** Guests and viewers only */
+    funded_amount?: number;
+}
+
+export interface WishItemCreate {
+    name: string;
+    priority?: number;
+    /** Empty or http(s) URL */
+    market_link?: string;
+    /** Empty or http(s) URL */
+    market_picture?: string;
+    market_price?: number;
+    /** ISO 4217 code */
+    market_currency?: string;
+    market_quantity?: number;
+    notes?: string;
+    /** Tags of the list owner; replaces the current set */
+    tag_ids?: number[];
+}
+
+export interface WishItemPatch {
+    name?: string;
+    priority?: number;
+    /** Empty or http(s) URL */
+    market_link?: string;
+    /** Empty or http(s) URL */
+    market_picture?: string;
+    market_price?: number;
+    /** ISO 4217 code */
+    market_currency?: string;
+    market_quantity?: number;
+    notes?: string;
+    /** Tags of the list owner; replaces the current set */
+    tag_ids?: number[];
+    status?: "pending" | "reserved" | "funded" | "purchased";
+}
+
+export interface WishListCreate {
+    name: string;
+    description?: string;
+    reservation_ttl_days?: number;
+    occasion_type?: "" | "birthday" | "wedding" | "new_year" | "other";
+    /** YYYY-MM-DD; empty string clears the date on update */
+    occasion_date?: string;
+    occasion_recurrence?: "none" | "yearly";
+    visibility?: Visibility;
+    /** Empty string removes protection. At most 72 bytes in UTF-8 */
+    passphrase?: string;
+}
+
+export interface WishListPatch {
+    name?: string;
+    description?: string;
+    reservation_ttl_days?: number;
+    occasion_type?: "" | "birthday" | "wedding" | "new_year" | "other";
+    /** YYYY-MM-DD; empty string clears the date on update */
+    occasion_date?: string;
+    occasion_recurrence?: "none" | "yearly";
+    visibility?: Visibility;
+    /** Empty string removes protection. At most 72 bytes in UTF-8 */
+    passphrase?: string;
+}
+
+export interface WishReservation {
+    id: number;
+    wish_id: number;
+    reserver: number;
+    quantity: number;
+    /** Unix time, seconds */
+    expires_at?: number;
+    /** Unix time, seconds */
+    created_at?: number;
+    /** Unix time, seconds */
+    updated_at?: number;
+}
+
+export interface WishContribution {
+    id: number;
+    wish_id: number;
+    contributor: number;
+    amount: number;
+    currency: string;
+    /** Unix time, seconds */
+    created_at?: number;
+    /** Unix time, seconds */
+    updated_at?: number;
+}
+
+export interface Member {
+    account_id: number;
+    role: Role;
+    creator: boolean;
+}
+
+export interface Invite {
+    id: number;
+    code: string;
+    wishlist_id: number;
+    role: Role;
+    created_by: number;
+    /** Unix time, seconds */
+    expires_at?: number;
+    /** Unix time, seconds */
+    created_at?: number;
+}
+
+export interface ShareToken {
+    id: number;
+    wishlist_id: number;
+    token: string;
+    label: string;
+    created_by: number;
+    /** Unix time, seconds */
+    expires_at?: number;
+    /** Unix time, seconds */
+    revoked_at?: number;
+    /** Unix time, seconds */
+    created_at?: number;
+}
+
+export interface AllowedUser {
+    id: number;
+    wishlist_id: number;
+    telegram_id: number;
+    /** Unix time, seconds */
+    created_at?: number;
+}
+
+export interface Version {
+    version: string;
+    commit?: string;
+    build_time?: string;
+    go_version: string;
+}
+
+export interface CalendarLink {
+    url: string;
+}
+
+export interface Readiness {
+    status: "ok" | "fail";
+    checks: Record<string, {
+        status: string;
+        error?: string;
+        detail?: unknown;
+    }>;
+    version: Version;
+}
+
+/** Exactly one of before and after */
+export interface WishItemMove {
+    /** Put the wish right before this one */
+    before?: number;
+    /** Put the wish right after this one */
+    after?: number;
+}
+
+export interface Tag {
+    id: number;
+    account_id: number;
+    name: string;
+    created_at: number;
+}
+
+export interface TagCreate {
+    name: string;
+}
+
+export interface WishItemTransfer {
+    wish_ids: number[];
+    /** Share code of a list where the user is editor or owner */
+    target_list: string;
+}
+
+/** GET /healthz */
+export type LivenessResponse = {
+    status: string;
+    version: Version;
+};
+
+/** GET /readyz */
+export type ReadinessResponse = Readiness;
+
+/** GET /api/v1/openapi.json */
+export type OpenapiResponse = Record<string, unknown>;
+
+/** GET /api/v1/health */
+export type HealthResponse = {
+    msg: string;
+};
+
+/** GET /api/v1/list */
+export type GetWishlistsResponse = {
+    wishlists: WishList[];
+    /** Empty on the last page */
+    next_cursor: string;
+    /** Number of items across all pages */
+    total: number;
+};
+
+/** POST /api/v1/list */
+export type CreateWishlistRequest = WishListCreate;
+
+/** POST /api/v1/list */
+export type CreateWishlistResponse = {
+    wishlist: WishList;
+};
+
+/** GET /api/v1/list/{listId} */
+export type GetWishlistResponse = {
+    wishlist: WishList;
+    /** Empty for guests */
+    role: string;
+};
+
+/** PATCH /api/v1/list/{listId} */
+export type UpdateWishlistRequest = WishListPatch;
+
+/** PATCH /api/v1/list/{listId} */
+export type UpdateWishlistResponse = {
+    wishlist: WishList;
+};
+
+/** DELETE /api/v1/list/{listId} */
+export type DeleteWishlistResponse = Message;
+
+/** GET /api/v1/list/{listId}/wishes */
+export type GetWishItemsResponse = {
+    wish_items: WishItem[];
+    /** Empty on the last page */
+    next_cursor: string;
+    /** Number of items across all pages */
+    total: number;
+};
+
+/** POST /api/v1/list/{listId}/wishes */
+export type CreateWishItemRequest = WishItemCreate;
+
+/** POST /api/v1/list/{listId}/wishes */
+export type CreateWishItemResponse = {
+    wish_item: WishItem;
+};
+
+/** POST /api/v1/list/{listId}/wishes/transfer */
+export type TransferWishItemsRequest = WishItemTransfer;
+
+/** POST /api/v1/list/{listId}/wishes/transfer */
+export type TransferWishItemsResponse = {
+    wish_items: WishItem[];
+};
+
+/** POST /api/v1/list/{listId}/wishes/copy */
+export type CopyWishItemsRequest = WishItemTransfer;
+
+/** POST /api/v1/list/{listId}/wishes/copy */
+export type CopyWishItemsResponse = {
+    wish_items: WishItem[];
+};
+
+/** GET /api/v1/list/{listId}/wishes/{wishId} */
+export type GetWishItemResponse = {
+    wish_item: WishItem;
+};
+
+/** PATCH /api/v1/list/{listId}/wishes/{wishId} */
+export type UpdateWishItemRequest = WishItemPatch;
+
+/** PATCH /api/v1/list/{listId}/wishes/{wishId} */
+export type UpdateWishItemResponse = {
+    wish_item: WishItem;
+};
+
+/** DELETE /api/v1/list/{listId}/wishes/{wishId} */
+export type DeleteWishItemResponse = Message;
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/move */
+export type MoveWishItemRequest = WishItemMove;
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/move */
+export type MoveWishItemResponse = {
+    wish_item: WishItem;
+};
+
+/** GET /api/v1/search/wishes */
+export type SearchWishItemsResponse = {
+    wish_items: WishItem[];
+    /** Empty on the last page */
+    next_cursor: string;
+    /** Number of items across all pages */
+    total: number;
+};
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/reservation */
+export type ReserveWishItemRequest = {
+    quantity?: number;
+};
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/reservation */
+export type ReserveWishItemResponse = {
+    reservation: WishReservation;
+};
+
+/** DELETE /api/v1/list/{listId}/wishes/{wishId}/reservation */
+export type CancelReservationResponse = Message;
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/reservation/renew */
+export type RenewReservationResponse = {
+    reservation: WishReservation;
+};
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/contribution */
+export type PledgeContributionRequest = {
+    amount: number;
+    currency?: string;
+};
+
+/** POST /api/v1/list/{listId}/wishes/{wishId}/contribution */
+export type PledgeContributionResponse = {
+    contribution: WishContribution;
+};
+
+/** DELETE /api/v1/list/{listId}/wishes/{wishId}/contribution */
+export type WithdrawContributionResponse = Message;
+
+/** GET /api/v1/list/{listId}/members */
+export type GetMembersResponse = {
+    members: Member[];
+};
+
+/** PATCH /api/v1/list/{listId}/members/{accountId} */
+export type UpdateMemberRequest = {
+    role: Role;
+};
+
+/** PATCH /api/v1/list/{listId}/members/{accountId} */
+export type UpdateMemberResponse = Message;
+
+/** DELETE /api/v1/list/{listId}/members/{accountId} */
+export type RemoveMemberResponse = Message;
+
+/** GET /api/v1/list/{listId}/invites */
+export type GetInvitesResponse = {
+    invites: Invite[];
+};
+
+/** POST /api/v1/list/{listId}/invites */
+export type CreateInviteRequest = {
+    role: Role;
+    expires_in_hours?: number;
+};
+
+/** POST /api/v1/list/{listId}/invites */
+export type CreateInviteResponse = {
+    invite: Invite;
+    link: string;
+};
+
+/** DELETE /api/v1/list/{listId}/invites/{code} */
+export type RevokeInviteResponse = Message;
+
+/** POST /api/v1/invites/{code}/accept */
+export type AcceptInviteResponse = {
+    wishlist: WishList;
+    role: Role;
+};
+
+/** GET /api/v1/list/{listId}/share-tokens */
+export type GetShareTokensResponse = {
+    share_tokens: ShareToken[];
+};
+
+/** POST /api/v1/list/{listId}/share-tokens */
+export type CreateShareTokenRequest = {
+    label?: string;
+    expires_in_hours?: number;
+};
+
+/** POST /api/v1/list/{listId}/share-tokens */
+export type CreateShareTokenResponse = {
+    share_token: ShareToken;
+};
+
+/** POST /api/v1/list/{listId}/share-tokens/{tokenId}/rotate */
+export type RotateShareTokenResponse = {
+    share_token: ShareToken;
+};
+
+/** DELETE /api/v1/list/{listId}/share-tokens/{tokenId} */
+export type RevokeShareTokenResponse = Message;
+
+/** POST /api/v1/list/{listId}/unlock */
+export type UnlockWishlistRequest = {
+    passphrase: string;
+};
+
+/** POST /api/v1/list/{listId}/unlock */
+export type UnlockWishlistResponse = {
+    grant: string;
+    /** Unix time, seconds */
+    expires_at: number;
+};
+
+/** GET /api/v1/list/{listId}/allowed */
+export type GetAllowedUsersResponse = {
+    allowed_users: AllowedUser[];
+};
+
+/** POST /api/v1/list/{listId}/allowed */
+export type AllowUserRequest = {
+    telegram_id: number;
+};
+
+/** POST /api/v1/list/{listId}/allowed */
+export type AllowUserResponse = {
+    allowed_user: AllowedUser;
+};
+
+/** DELETE /api/v1/list/{listId}/allowed/{telegramId} */
+export type DisallowUserResponse = Message;
+
+/** GET /api/v1/users/{userId}/lists */
+export type GetPublicWishlistsResponse = {
+    wishlists: WishList[];
+    /** Empty on the last page */
+    next_cursor: string;
+    /** Number of lists across all pages */
+    total: number;
+};
+
+/** DELETE /api/v1/account */
+export type DeleteAccountResponse = Message;
+
+/** GET /api/v1/account/calendar */
+export type GetCalendarLinkResponse = CalendarLink;
+
+/** POST /api/v1/account/calendar/rotate */
+export type RotateCalendarLinkResponse = CalendarLink;
+
+/** GET /api/v1/account/tags */
+export type GetTagsResponse = {
+    tags: Tag[];
+};
+
+/** POST /api/v1/account/tags */
+export type CreateTagRequest = TagCreate;
+
+/** POST /api/v1/account/tags */
+export type CreateTagResponse = {
+    tag: Tag;
+};
+
+/** PATCH /api/v1/account/tags/{tagId} */
+export type RenameTagRequest = TagCreate;
+
+/** PATCH /api/v1/account/tags/{tagId} */
+export type RenameTagResponse = {
+    tag: Tag;
+};
+
+/** DELETE /api/v1/account/tags/{tagId} */
+export type DeleteTagResponse = Message;
+
+/** GET /api/v1/list/{listId}/tags */
+export type GetListTagsResponse = {
+    tags: Tag[];
+};
+
+/** HTTP-метод каждой операции /api/v1/ */
+export const operations = {
+    health: "GET",
+    getWishlists: "GET",
+    createWishlist: "POST",
+    getWishlist: "GET",
+    updateWishlist: "PATCH",
+    deleteWishlist: "DELETE",
+    getWishItems: "GET",
+    createWishItem: "POST",
+    transferWishItems: "POST",
+    copyWishItems: "POST",
+    getWishItem: "GET",
+    updateWishItem: "PATCH",
+    deleteWishItem: "DELETE",
+    moveWishItem: "POST",
+    searchWishItems: "GET",
+    reserveWishItem: "POST",
+    cancelReservation: "DELETE",
+    renewReservation: "POST",
+    pledgeContribution: "POST",
+    withdrawContribution: "DELETE",
+    getMembers: "GET",
+    updateMember: "PATCH",
+    removeMember: "DELETE",
+    getInvites: "GET",
+    createInvite: "POST",
+    revokeInvite: "DELETE",
+    acceptInvite: "POST",
+    getShareTokens: "GET",
+    createShareToken: "POST",
+    rotateShareToken: "POST",
+    revokeShareToken: "DELETE",
+    unlockWishlist: "POST",
+    getAllowedUsers: "GET",
+    allowUser: "POST",
+    disallowUser: "DELETE",
+    getPublicWishlists: "GET",
+    deleteAccount: "DELETE",
+    getCalendarLink: "GET",
+    rotateCalendarLink: "POST",
+    getTags: "GET",
+    createTag: "POST",
+    renameTag: "PATCH",
+    deleteTag: "DELETE",
+    getListTags: "GET",
+} as const;
+
+/** Путь относительно /api/v1/, тела запроса и ответа каждой операции */
+export interface Operations {
+    health: {
+        path: `health`;
+        request: null;
+        response: HealthResponse;
+    };
+    getWishlists: {
+        path: `list` | `list?${string}`;
+        request: null;
+        response: GetWishlistsResponse;
+    };
+    createWishlist: {
+        path: `list`;
+        request: CreateWishlistRequest;
+        response: CreateWishlistResponse;
+    };
+    getWishlist: {
+        path: `list/${string}`;
+        request: null;
+        response: GetWishlistResponse;
+    };
+    updateWishlist: {
+        path: `list/${string}`;
+        request: UpdateWishlistRequest;
+        response: UpdateWishlistResponse;
+    };
+    deleteWishlist: {
+        path: `list/${string}`;
+        request: null;
+        response: DeleteWishlistResponse;
+    };
+    getWishItems: {
+        path: `list/${string}/wishes` | `list/${string}/wishes?${string}`;
+        request: null;
+        response: GetWishItemsResponse;
+    };
+    createWishItem: {
+        path: `list/${string}/wishes`;
+        request: CreateWishItemRequest;
+        response: CreateWishItemResponse;
+    };
+    transferWishItems: {
+        path: `list/${string}/wishes/transfer`;
+        request: TransferWishItemsRequest;
+        response: TransferWishItemsResponse;
+    };
+    copyWishItems: {
+        path: `list/${string}/wishes/copy`;
+        request: CopyWishItemsRequest;
+        response: CopyWishItemsResponse;
+    };
+    getWishItem: {
+        path: `list/${string}/wishes/${string}`;
+        request: null;
+        response: GetWishItemResponse;
+    };
+    updateWishItem: {
+        path: `list/${string}/wishes/${string}`;
+        request: UpdateWishItemRequest;
+        response: UpdateWishItemResponse;
+    };
+    deleteWishItem: {
+        path: `list/${string}/wishes/${string}`;
+        request: null;
+        response: DeleteWishItemResponse;
+    };
+    moveWishItem: {
+        path: `list/${string}/wishes/${string}/move`;
+        request: MoveWishItemRequest;
+        response: MoveWishItemResponse;
+    };
+    searchWishItems: {
+        path: `search/wishes` | `search/wishes?${string}`;
+        request: null;
+        response: SearchWishItemsResponse;
+    };
+    reserveWishItem: {
+        path: `list/${string}/wishes/${string}/reservation`;
+        request: ReserveWishItemRequest;
+        response: ReserveWishItemResponse;
+    };
+    cancelReservation: {
+        path: `list/${string}/wishes/${string}/reservation`;
+        request: null;
+        response: CancelReservationResponse;
+    };
+    renewReservation: {
+        path: `list/${string}/wishes/${string}/reservation/renew`;
+        request: null;
+        response: RenewReservationResponse;
+    };
+    pledgeContribution: {
+        path: `list/${string}/wishes/${string}/contribution`;
+        request: PledgeContributionRequest;
+        response: PledgeContributionResponse;
+    };
+    withdrawContribution: {
+        path: `list/${string}/wishes/${string}/contribution`;
+        request: null;
+        response: WithdrawContributionResponse;
+    };
+    getMembers: {
+        path: `list/${string}/members`;
+        request: null;
+        response: GetMembersResponse;
+    };
+    updateMember: {
+        path: `list/${string}/members/${string}`;
+        request: UpdateMemberRequest;
+        response: UpdateMemberResponse;
+    };
+    removeMember: {
+        path: `list/${string}/members/${string}`;
+        request: null;
+        response: RemoveMemberResponse;
+    };
+    getInvites: {
+        path: `list/${string}/invites`;
+        request: null;
+        response: GetInvitesResponse;
+    };
+    createInvite: {
+        path: `list/${string}/invites`;
+        request: CreateInviteRequest;
+        response: CreateInviteResponse;
+    };
+    revokeInvite: {
+        path: `list/${string}/invites/${string}`;
+        request: null;
+        response: RevokeInviteResponse;
+    };
+    acceptInvite: {
+        path: `invites/${string}/accept`;
+        request: null;
+        response: AcceptInviteResponse;
+    };
+    getShareTokens: {
+        path: `list/${string}/share-tokens`;
+        request: null;
+        response: GetShareTokensResponse;
+    };
+    createShareToken: {
+        path: `list/${string}/share-tokens`;
+        request: CreateShareTokenRequest;
+        response: CreateShareTokenResponse;
+    };
+    rotateShareToken: {
+        path: `list/${string}/share-tokens/${string}/rotate`;
+        request: null;
+        response: RotateShareTokenResponse;
+    };
+    revokeShareToken: {
+        path: `list/${string}/share-tokens/${string}`;
+        request: null;
+        response: RevokeShareTokenResponse;
+    };
+    unlockWishlist: {
+        path: `list/${string}/unlock`;
+        request: UnlockWishlistRequest;
+        response: UnlockWishlistResponse;
+    };
+    getAllowedUsers: {
+        path: `list/${string}/allowed`;
+        request: null;
+        response: GetAllowedUsersResponse;
+    };
+    allowUser: {
+        path: `list/${string}/allowed`;
+        request: AllowUserRequest;
+        response: AllowUserResponse;
+    };
+    disallowUser: {
+        path: `list/${string}/allowed/${string}`;
+        request: null;
+        response: DisallowUserResponse;
+    };
+    getPublicWishlists: {
+        path: `users/${string}/lists` | `users/${string}/lists?${string}`;
+        request: null;
+        response: GetPublicWishlistsResponse;
+    };
+    deleteAccount: {
+        path: `account`;
+        request: null;
+        response: DeleteAccountResponse;
+    };
+    getCalendarLink: {
+        path: `account/calendar`;
+        request: null;
+        response: GetCalendarLinkResponse;
+    };
+    rotateCalendarLink: {
+        path: `account/calendar/rotate`;
+        request: null;
+        response: RotateCalendarLinkResponse;
+    };
+    getTags: {
+        path: `account/tags`;
+        request: null;
+        response: GetTagsResponse;
+    };
+    createTag: {
+        path: `account/tags`;
+        request: CreateTagRequest;
+        response: CreateTagResponse;
+    };
+    renameTag: {
+        path: `account/tags/${string}`;
+        request: RenameTagRequest;
+        response: RenameTagResponse;
+    };
+    deleteTag: {
+        path: `account/tags/${string}`;
+        request: null;
+        response: DeleteTagResponse;
+    };
+    getListTags: {
+        path: `list/${string}/tags`;
+        request: null;
+        response: GetListTagsResponse;
+    };
+}
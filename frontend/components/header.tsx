@@ -4,10 +4,12 @@ import AddIcon from '@mui/icons-material/Add';
 import CloseIcon from '@mui/icons-material/Close';
 import FavoriteIcon from '@mui/icons-material/Favorite';
 import ListAltIcon from '@mui/icons-material/ListAlt';
+import ShareIcon from '@mui/icons-material/Share';
 import * as React from "react";
 import {JSX} from "react";
 import {useNavigate, useParams} from "react-router";
 import {auto} from "@popperjs/core";
+import {FetchShareToken} from "../api/api";
 
 
 const ProfileMenu =   (
@@ -76,6 +78,16 @@ export default function Header({title, addType}: { title: string, addType: strin
             return
         }
     }
+    const handleShareButtonClick = () => {
+        // делимся ссылкой по токену: share_code открывает список только участникам
+        FetchShareToken(params.id!).then((token) => {
+            if (!token) {
+                console.error("No share link for list", params.id);
+                return
+            }
+            return navigator.clipboard.writeText(`${window.location.origin}/wishlist/${token}`);
+        }).catch((e) => console.error("Error sharing list", e));
+    }
     return (
     <>
         <AppBar position="static" sx={{height: 50, width: "100%", justifyContent: "center",
@@ -97,6 +109,15 @@ export default function Header({title, addType}: { title: string, addType: strin
                 <Typography variant="h6" component="div" sx={{flexGrow: 1}}>
                     {title}
                 </Typography>
+                {addType === "wish" && params.id &&
+                    <IconButton
+                        size="medium"
+                        color="inherit"
+                        aria-label="share"
+                        onClick={handleShareButtonClick}
+                    >
+                        <ShareIcon/>
+                    </IconButton>}
                 {addType &&
                     <IconButton
                         size="medium"
@@ -2,7 +2,7 @@ import {JSX, useEffect, useState} from "react";
 import {useNavigate} from "react-router";
 import {Card, CardActionArea, CardContent, Typography, Box} from "@mui/material";
 import {Edit, DeleteForever} from "@mui/icons-material";
-import {DeleteWishlist, EditWishlist, FetchFavorites, FetchLists} from "../api/api";
+import {DeleteWishlist, EditWishlist, FetchLists} from "../api/api";
 import {List} from "../api/interfaces"
 import {CreateWishlistDialog} from "./dialogs";
 import * as React from "react";
@@ -159,9 +159,8 @@ const WishlistCard = ({cardObject, onEdit, onDelete, onCardClick}: {
 //     return cards;
 // };
 
-export default function CardsList({isEditable}: { isEditable: boolean }): JSX.Element {
+export default function CardsList(): JSX.Element {
     // Возвращает списки желаний для ListsPage
-    // is_favorites - true, если нужно отобразить сохраненные чужие списки
     const [lists, setLists] = useState(Array<List>());
     const [dataLoaded, setDataLoaded] = useState(false);
     const [createListDialogOpen, setCreateListDialogOpen] = useState(false);
@@ -172,29 +171,17 @@ export default function CardsList({isEditable}: { isEditable: boolean }): JSX.El
         if (dataLoaded) {
             return;
         }
-        // Fetch lists and favorites from API
-        const fetchFavorites = async () => {
-            const data = await FetchFavorites();
-            setLists(data);
-        }
-
+        // Fetch lists from API
         const fetchLists = async () => {
             const data = await FetchLists();
             setLists(data);
         }
 
-        if (isEditable) {
-            fetchFavorites().then(() => {
-                    setDataLoaded(true)
-                }
-            )
-        } else {
-            fetchLists().then(() => {
-                    setDataLoaded(true)
-                }
-            )
-        }
-    }, [isEditable]);
+        fetchLists().then(() => {
+                setDataLoaded(true)
+            }
+        )
+    }, []);
 
     const cardClickHandler = (id: string) => {
         navigate(`/wishlist/${id}`);
@@ -1,14 +1,10 @@
 import React from 'react';
 import {useEffect} from 'react';
 import {Container} from "@mui/material";
-import {useLocation} from "react-router";
 import Header from '../components/header';
 import CardsList from "../components/lists";
 
 export default function ListsPage() {
-    const location = useLocation();
-    const isFavorites = location.pathname.includes('favorites');
-
     useEffect(() => {
     }, [])
 
@@ -29,7 +25,7 @@ export default function ListsPage() {
             }
         }}>
 
-            {isFavorites ? <CardsList is_favorites={true}/> : <CardsList is_favorites={false}/>}
+            <CardsList/>
         </Container>
         </>
     )
@@ -179,9 +179,9 @@ export default function WishDetailsPage() {
 
         EditWish(params.id, params.wishid,
             {
-                "name": formData.get("title"),
-                "market_link": formData.get("product_url"),
-                "market_picture": formData.get("picture_url"),
+                "name": formData.get("title") as string,
+                "market_link": formData.get("product_url") as string,
+                "market_picture": formData.get("picture_url") as string,
                 "market_price": productPrice,
             }
         ).then(() => {